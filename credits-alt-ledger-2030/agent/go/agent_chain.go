@@ -10,7 +10,6 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
@@ -31,6 +30,7 @@ type AgentOperation struct {
 	Data     map[string]interface{} `json:"data"`
 	Nonce    uint64                 `json:"nonce"`
 	GasLimit uint64                 `json:"gas_limit"`
+	GasPrice uint64                 `json:"gas_price"`
 }
 
 // AgentTransaction represents a transaction on an agent chain
@@ -50,6 +50,7 @@ type AgentBlock struct {
 	PreviousHash TransactionHash     `json:"previous_hash"`
 	Timestamp    uint64              `json:"timestamp"`
 	Transactions []AgentTransaction  `json:"transactions"`
+	TxRoot       [32]byte            `json:"tx_root"`
 	StateRoot    [32]byte            `json:"state_root"`
 	Hash         TransactionHash     `json:"hash"`
 }
@@ -65,18 +66,58 @@ type AgentChainState struct {
 	StateData      map[string][]byte `json:"state_data"`
 	DAGHeight      uint64            `json:"dag_height"`
 	LastSyncTime   uint64            `json:"last_sync_time"`
+	HTLCLocks      map[[32]byte]HTLCLock `json:"htlc_locks"`
+	// Receivables holds value claimed by a counterparty out of this chain's
+	// own escrow: a htlc_claim by anyone other than Owner credits the
+	// claimant's address here instead of Owner's Balance, since this chain
+	// has no way to credit Balance on a chain it doesn't own. The
+	// counterparty's own chain settles it into their spendable Balance via
+	// SettleReceivable.
+	Receivables    map[AgentAddress]uint64 `json:"receivables"`
 }
 
+// HTLCLock represents value escrowed by a htlc_lock operation: redeemable by
+// Recipient with the preimage of Hash, or refundable to Locker once Timeout
+// has passed.
+type HTLCLock struct {
+	Hash      [32]byte     `json:"hash"`
+	Amount    uint64       `json:"amount"`
+	Locker    AgentAddress `json:"locker"`
+	Recipient AgentAddress `json:"recipient"`
+	Timeout   time.Time    `json:"timeout"`
+	Claimed   bool         `json:"claimed"`
+	Refunded  bool         `json:"refunded"`
+}
+
+// Default block-packing limits applied by CommitBlock when none have been
+// set via SetBlockLimits.
+const (
+	defaultMaxBlockSize = 1 << 20  // 1 MiB of canonically-encoded transactions
+	defaultMaxBlockGas  = 8000000
+)
+
 // PersonalAgentChain manages a personal agent chain
 type PersonalAgentChain struct {
 	mu           sync.RWMutex
 	state        AgentChainState
-	pendingTxs   []AgentTransaction
+	store        Store
+	stateTree    *MPT
+	mempool      *MemPool
+	maxBlockSize uint64
+	maxBlockGas  uint64
 	coordinator  *CrossAgentCoordinator
 	cnsResolver  CNSResolver
 	eventHandlers map[string][]EventHandler
+	operationHandlers map[string]OperationHandler
+	precompiles  *PrecompileRegistry
+	signer       Signer
 }
 
+// OperationHandler applies a transaction whose Operation.Type it was
+// registered under to the chain's state. It runs with pac.mu already held,
+// the same way the built-in transfer/CNS/HTLC handlers do.
+type OperationHandler func(tx AgentTransaction) error
+
 // EventHandler represents a callback for chain events
 type EventHandler func(event ChainEvent)
 
@@ -96,14 +137,27 @@ type CNSResolver interface {
 	Transfer(namespace, name string, newOwner AgentAddress) error
 }
 
-// NewPersonalAgentChain creates a new personal agent chain
-func NewPersonalAgentChain(owner AgentAddress, cnsName string) (*PersonalAgentChain, error) {
-	chainID := generateChainID(owner, cnsName)
-	
-	chain := &PersonalAgentChain{
-		state: AgentChainState{
+// NewPersonalAgentChain creates a personal agent chain owned by signer,
+// persisting blocks, state, and tx lookups to store. A nil store keeps
+// everything in memory only, matching the chain's behavior before Store
+// existed. If store already holds a state snapshot (i.e. this chain is
+// being reopened after a restart), that snapshot is rehydrated instead of
+// starting over at height 0.
+func NewPersonalAgentChain(signer Signer, store Store, cnsName string) (*PersonalAgentChain, error) {
+	if store == nil {
+		store = newMemStore()
+	}
+
+	owner := signer.PublicKey().Address()
+
+	state, found, err := store.GetState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chain state: %v", err)
+	}
+	if !found {
+		state = &AgentChainState{
 			Owner:         owner,
-			ChainID:       chainID,
+			ChainID:       generateChainID(owner, cnsName),
 			Height:        0,
 			LastBlockHash: TransactionHash{},
 			Nonce:         0,
@@ -111,14 +165,77 @@ func NewPersonalAgentChain(owner AgentAddress, cnsName string) (*PersonalAgentCh
 			StateData:     make(map[string][]byte),
 			DAGHeight:     0,
 			LastSyncTime:  uint64(time.Now().Unix()),
-		},
-		pendingTxs:    make([]AgentTransaction, 0),
+			HTLCLocks:     make(map[[32]byte]HTLCLock),
+			Receivables:   make(map[AgentAddress]uint64),
+		}
+	}
+	if state.Receivables == nil {
+		state.Receivables = make(map[AgentAddress]uint64)
+	}
+
+	chain := &PersonalAgentChain{
+		signer:        signer,
+		store:         store,
+		state:         *state,
+		stateTree:     NewMPT(),
 		eventHandlers: make(map[string][]EventHandler),
+		maxBlockSize:  defaultMaxBlockSize,
+		maxBlockGas:   defaultMaxBlockGas,
 	}
-	
+	chain.syncStateTree()
+	// Reopening a chain from a persisted store rehydrates state.StateData
+	// (which covers CNS/precompile-written keys too, since they all go
+	// through SetStateData) but starts from a fresh, empty stateTree; put
+	// every entry back so the rebuilt root matches the one this chain
+	// produced before it shut down.
+	for key, value := range chain.state.StateData {
+		chain.stateTree.Put(key, value)
+	}
+	chain.operationHandlers = make(map[string]OperationHandler)
+	chain.registerBuiltinOperations()
+
+	// CNS is the chain's one built-in precompile; RegisterPrecompile lets
+	// callers add more (transfer bridge, signature verifier, ...).
+	chain.precompiles = NewPrecompileRegistry()
+	if err := chain.precompiles.Register(CNSPrecompile{}); err != nil {
+		return nil, fmt.Errorf("failed to register CNS precompile: %v", err)
+	}
+
+	// The mempool's emit callback runs with pac.mu already held (it is only
+	// ever invoked from methods that lock pac.mu before touching the
+	// mempool), so it stamps BlockHeight and forwards straight to
+	// emitEvent rather than re-entering through a locking helper.
+	chain.mempool = NewMemPool(DefaultMemPoolConfig(), AllowAllPolicy(), func(event ChainEvent) {
+		event.BlockHeight = chain.state.Height
+		chain.emitEvent(event)
+	})
+
 	return chain, nil
 }
 
+// SetMemPoolPolicy replaces the admission policy applied to every
+// transaction before it enters the mempool.
+func (pac *PersonalAgentChain) SetMemPoolPolicy(policy Policy) {
+	pac.mu.Lock()
+	defer pac.mu.Unlock()
+	pac.mempool.SetPolicy(policy)
+}
+
+// MemPoolLen returns the number of transactions currently pending.
+func (pac *PersonalAgentChain) MemPoolLen() int {
+	return pac.mempool.Len()
+}
+
+// SetBlockLimits overrides the packing limits CommitBlock uses when pulling
+// transactions from the mempool. A zero value leaves the corresponding
+// limit unbounded.
+func (pac *PersonalAgentChain) SetBlockLimits(maxSize, maxGas uint64) {
+	pac.mu.Lock()
+	defer pac.mu.Unlock()
+	pac.maxBlockSize = maxSize
+	pac.maxBlockGas = maxGas
+}
+
 // GetState returns the current chain state
 func (pac *PersonalAgentChain) GetState() AgentChainState {
 	pac.mu.RLock()
@@ -126,29 +243,102 @@ func (pac *PersonalAgentChain) GetState() AgentChainState {
 	return pac.state
 }
 
+// GetBlockByHash returns the committed block with the given hash.
+func (pac *PersonalAgentChain) GetBlockByHash(hash TransactionHash) (*AgentBlock, error) {
+	block, found, err := pac.store.GetBlock(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block %x: %v", hash, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("no block with hash %x", hash)
+	}
+	return block, nil
+}
+
+// GetBlockByHeight returns the committed block at the given height.
+func (pac *PersonalAgentChain) GetBlockByHeight(height uint64) (*AgentBlock, error) {
+	block, found, err := pac.store.GetBlockByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block at height %d: %v", height, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("no block at height %d", height)
+	}
+	return block, nil
+}
+
+// GetTransaction returns a committed transaction by ID, resolving it
+// through the store's tx lookup index rather than scanning every block.
+func (pac *PersonalAgentChain) GetTransaction(txID TransactionHash) (*AgentTransaction, error) {
+	var height uint64
+	var index int
+	found := false
+
+	key := StoreTxPrefix + txID.String()
+	err := pac.store.Iterate(key, func(k string, value []byte) bool {
+		if k != key {
+			return false
+		}
+		h, i, ok := DecodeTxLookup(value)
+		if ok {
+			height, index, found = h, i, true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up transaction %x: %v", txID, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("no transaction %x", txID)
+	}
+
+	block, err := pac.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(block.Transactions) {
+		return nil, fmt.Errorf("tx lookup for %x points outside block %d", txID, height)
+	}
+	return &block.Transactions[index], nil
+}
+
 // CreateTransaction creates a new transaction on the agent chain
 func (pac *PersonalAgentChain) CreateTransaction(to AgentAddress, operation AgentOperation, value uint64) (*AgentTransaction, error) {
 	pac.mu.Lock()
 	defer pac.mu.Unlock()
-	
-	// Generate transaction ID
-	txID := pac.generateTransactionID(to, operation, value)
-	
+
+	// A transaction addressed at a precompile is checked against that
+	// precompile's declared gas cost up front, the same way a malformed
+	// operation would otherwise only fail once it reached processTx.
+	if contract, ok := pac.precompiles.Get(to); ok {
+		args, err := precompileArgs(operation.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid precompile call: %v", err)
+		}
+		if required := contract.RequiredGas(operation.Type, args); operation.GasLimit < required {
+			return nil, fmt.Errorf("gas limit %d is below precompile's required %d", operation.GasLimit, required)
+		}
+	}
+
 	tx := AgentTransaction{
-		ID:        txID,
 		From:      pac.state.Owner,
 		To:        to,
 		Operation: operation,
 		Value:     value,
 		Timestamp: uint64(time.Now().UnixMilli()),
 	}
-	
-	// Sign transaction (simplified - would use actual cryptographic signing)
-	tx.Signature = pac.signTransaction(tx)
-	
-	// Add to pending transactions
-	pac.pendingTxs = append(pac.pendingTxs, tx)
-	
+
+	// The transaction ID is the hash of its canonical encoding, so it is
+	// deterministic across nodes rather than derived from wall-clock nonces.
+	tx.ID = TransactionHash(sha256.Sum256(encodeTransactionForSigning(tx)))
+	tx.Signature = pac.signer.Sign(encodeTransactionForSigning(tx))
+
+	// Admit to the mempool (policy checks, replacement, eviction); this
+	// also emits tx_added/tx_evicted/tx_replaced as appropriate.
+	if err := pac.mempool.Add(tx); err != nil {
+		return nil, err
+	}
+
 	// Emit event
 	pac.emitEvent(ChainEvent{
 		Type:        "transaction_created",
@@ -156,50 +346,97 @@ func (pac *PersonalAgentChain) CreateTransaction(to AgentAddress, operation Agen
 		Timestamp:   tx.Timestamp,
 		BlockHeight: pac.state.Height,
 	})
-	
+
 	return &tx, nil
 }
 
-// CommitBlock commits pending transactions to a new block
+// CommitBlock commits pending transactions to a new block. Transactions are
+// pulled from the mempool in priority order, packed up to the chain's block
+// limits, and re-validated against the policy checked at admission time.
 func (pac *PersonalAgentChain) CommitBlock() (*AgentBlock, error) {
 	pac.mu.Lock()
 	defer pac.mu.Unlock()
-	
-	if len(pac.pendingTxs) == 0 {
+
+	pending := pac.mempool.Pending(pac.maxBlockSize, pac.maxBlockGas)
+	if len(pending) == 0 {
 		return nil, fmt.Errorf("no pending transactions to commit")
 	}
-	
+
+	ownerPub := pac.signer.PublicKey()
+	for _, tx := range pending {
+		if tx.From != pac.state.Owner {
+			continue // signed by a counterparty; verified by its own chain
+		}
+		if !VerifyTransaction(tx, ownerPub) {
+			return nil, fmt.Errorf("invalid signature on transaction %x", tx.ID)
+		}
+	}
+
 	// Create new block
 	block := AgentBlock{
 		Height:       pac.state.Height + 1,
 		PreviousHash: pac.state.LastBlockHash,
 		Timestamp:    uint64(time.Now().UnixMilli()),
-		Transactions: make([]AgentTransaction, len(pac.pendingTxs)),
+		Transactions: pending,
 	}
-	
-	copy(block.Transactions, pac.pendingTxs)
-	
-	// Calculate state root (simplified)
+
+	// Process transactions and update state before computing the roots,
+	// so StateRoot commits to the state this block produces rather than
+	// the state left over from the block before it.
+	for _, tx := range block.Transactions {
+		if err := pac.processTx(tx); err != nil {
+			return nil, fmt.Errorf("failed to process transaction %x: %v", tx.ID, err)
+		}
+	}
+
+	pac.state.Height = block.Height
+	pac.state.Nonce++
+
+	// Calculate the transaction and state roots
+	block.TxRoot = merkleRootOfTxs(block.Transactions)
 	block.StateRoot = pac.calculateStateRoot()
-	
+
 	// Calculate block hash
 	block.Hash = pac.calculateBlockHash(block)
-	
-	// Update chain state
-	pac.state.Height = block.Height
 	pac.state.LastBlockHash = block.Hash
-	pac.state.Nonce++
-	
-	// Process transactions and update state
-	for _, tx := range block.Transactions {
-		if err := pac.processTx(tx); err != nil {
-			return nil, fmt.Errorf("failed to process transaction %x: %v", tx.ID, err)
+
+	// Persist the block, the resulting state, and every tx lookup as one
+	// atomic unit, so a crash mid-commit cannot leave the store with a
+	// block but no matching state (or vice versa).
+	err := pac.store.Batch(func(b Batch) error {
+		if err := b.PutBlock(block); err != nil {
+			return fmt.Errorf("failed to persist block %x: %v", block.Hash, err)
+		}
+		if err := b.PutState(pac.state); err != nil {
+			return fmt.Errorf("failed to persist chain state: %v", err)
 		}
+		for i, tx := range block.Transactions {
+			if err := b.PutTxLookup(tx.ID, block.Height, i); err != nil {
+				return fmt.Errorf("failed to persist tx lookup for %x: %v", tx.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	
-	// Clear pending transactions
-	pac.pendingTxs = pac.pendingTxs[:0]
-	
+
+	// Index the block for from/to/op history queries, if the Store
+	// supports it. This is a derived index (rebuildable via Replay), so it
+	// is kept best-effort outside the atomic block/state/tx-lookup batch
+	// above rather than folded into the Batch interface every Store must
+	// implement.
+	if hi, ok := pac.store.(HistoryIndex); ok {
+		if err := hi.PutHistoryEntries(block); err != nil {
+			return nil, fmt.Errorf("failed to index block %d for history: %v", block.Height, err)
+		}
+	}
+
+	// Remove the committed transactions from the mempool
+	for _, tx := range block.Transactions {
+		pac.mempool.Remove(tx.ID)
+	}
+
 	// Emit event
 	pac.emitEvent(ChainEvent{
 		Type:        "block_committed",
@@ -207,7 +444,7 @@ func (pac *PersonalAgentChain) CommitBlock() (*AgentBlock, error) {
 		Timestamp:   block.Timestamp,
 		BlockHeight: block.Height,
 	})
-	
+
 	return &block, nil
 }
 
@@ -273,9 +510,10 @@ func (pac *PersonalAgentChain) ResolveCNSName(namespace, name string) (*AgentAdd
 func (pac *PersonalAgentChain) SetStateData(key string, value []byte) {
 	pac.mu.Lock()
 	defer pac.mu.Unlock()
-	
+
 	pac.state.StateData[key] = value
-	
+	pac.stateTree.Put(key, value)
+
 	// Emit event
 	pac.emitEvent(ChainEvent{
 		Type: "state_updated",
@@ -292,10 +530,25 @@ func (pac *PersonalAgentChain) SetStateData(key string, value []byte) {
 func (pac *PersonalAgentChain) GetStateData(key string) []byte {
 	pac.mu.RLock()
 	defer pac.mu.RUnlock()
-	
+
 	return pac.state.StateData[key]
 }
 
+// GetStateProof returns an inclusion proof for key's current value in the
+// state tree, along with the root it proves against. A cross-agent reader
+// can check the proof against a counterparty's claimed root with
+// VerifyStateProof instead of pulling their whole chain.
+func (pac *PersonalAgentChain) GetStateProof(key string) (*Proof, [32]byte, error) {
+	pac.mu.RLock()
+	defer pac.mu.RUnlock()
+
+	proof, err := pac.stateTree.GetStateProof(key)
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+	return proof, pac.stateTree.Root(), nil
+}
+
 // SyncWithDAG synchronizes the agent chain with the main DAG
 func (pac *PersonalAgentChain) SyncWithDAG(dagHeight uint64) error {
 	pac.mu.Lock()
@@ -346,86 +599,31 @@ func (pac *PersonalAgentChain) SetCNSResolver(resolver CNSResolver) {
 
 // Private methods
 
-func (pac *PersonalAgentChain) generateTransactionID(to AgentAddress, operation AgentOperation, value uint64) TransactionHash {
-	hasher := sha256.New()
-	
-	// Hash transaction components
-	hasher.Write(pac.state.Owner[:])
-	hasher.Write(to[:])
-	hasher.Write([]byte(operation.Type))
-	
-	// Hash operation data
-	if operationBytes, err := json.Marshal(operation.Data); err == nil {
-		hasher.Write(operationBytes)
-	}
-	
-	// Hash value and timestamp
-	valueBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(valueBytes, value)
-	hasher.Write(valueBytes)
-	
-	timestampBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(timestampBytes, uint64(time.Now().UnixNano()))
-	hasher.Write(timestampBytes)
-	
-	hash := hasher.Sum(nil)
-	var txID TransactionHash
-	copy(txID[:], hash)
-	return txID
-}
+// syncStateTree refreshes the reserved StateKey* leaves from the chain's
+// fixed-layout fields (StateData leaves are kept current by SetStateData
+// itself). Callers must hold pac.mu.
+func (pac *PersonalAgentChain) syncStateTree() {
+	pac.stateTree.Put(StateKeyOwner, pac.state.Owner[:])
 
-func (pac *PersonalAgentChain) signTransaction(tx AgentTransaction) []byte {
-	// Simplified signing - in production would use actual cryptographic signing
-	hasher := sha256.New()
-	
-	hasher.Write(tx.ID[:])
-	hasher.Write(tx.From[:])
-	hasher.Write(tx.To[:])
-	
-	if txBytes, err := json.Marshal(tx.Operation); err == nil {
-		hasher.Write(txBytes)
-	}
-	
-	valueBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(valueBytes, tx.Value)
-	hasher.Write(valueBytes)
-	
-	timestampBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(timestampBytes, tx.Timestamp)
-	hasher.Write(timestampBytes)
-	
-	return hasher.Sum(nil)
-}
-
-func (pac *PersonalAgentChain) calculateStateRoot() [32]byte {
-	hasher := sha256.New()
-	
-	// Hash state components
-	hasher.Write(pac.state.Owner[:])
-	hasher.Write(pac.state.ChainID[:])
-	
-	heightBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(heightBytes, pac.state.Height)
-	hasher.Write(heightBytes)
-	
 	nonceBytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(nonceBytes, pac.state.Nonce)
-	hasher.Write(nonceBytes)
-	
+	pac.stateTree.Put(StateKeyNonce, nonceBytes)
+
 	balanceBytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(balanceBytes, pac.state.Balance)
-	hasher.Write(balanceBytes)
-	
-	// Hash state data
-	for key, value := range pac.state.StateData {
-		hasher.Write([]byte(key))
-		hasher.Write(value)
-	}
-	
-	hash := hasher.Sum(nil)
-	var stateRoot [32]byte
-	copy(stateRoot[:], hash)
-	return stateRoot
+	pac.stateTree.Put(StateKeyBalance, balanceBytes)
+
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, pac.state.Height)
+	pac.stateTree.Put(StateKeyHeight, heightBytes)
+}
+
+// calculateStateRoot returns the root of the Merkle state tree after
+// resyncing the reserved fields (Owner/Nonce/Balance/Height) that change
+// outside of SetStateData.
+func (pac *PersonalAgentChain) calculateStateRoot() [32]byte {
+	pac.syncStateTree()
+	return pac.stateTree.Root()
 }
 
 func (pac *PersonalAgentChain) calculateBlockHash(block AgentBlock) TransactionHash {
@@ -443,12 +641,8 @@ func (pac *PersonalAgentChain) calculateBlockHash(block AgentBlock) TransactionH
 	hasher.Write(timestampBytes)
 	
 	hasher.Write(block.StateRoot[:])
-	
-	// Hash all transactions
-	for _, tx := range block.Transactions {
-		hasher.Write(tx.ID[:])
-	}
-	
+	hasher.Write(block.TxRoot[:])
+
 	hash := hasher.Sum(nil)
 	var blockHash TransactionHash
 	copy(blockHash[:], hash)
@@ -456,14 +650,209 @@ func (pac *PersonalAgentChain) calculateBlockHash(block AgentBlock) TransactionH
 }
 
 func (pac *PersonalAgentChain) processTx(tx AgentTransaction) error {
-	switch tx.Operation.Type {
-	case "transfer":
-		return pac.processTransfer(tx)
-	case "cns_register":
-		return pac.processCNSRegister(tx)
-	default:
+	if contract, ok := pac.precompiles.Get(tx.To); ok {
+		return pac.runPrecompile(contract, tx)
+	}
+
+	handler, ok := pac.operationHandlers[tx.Operation.Type]
+	if !ok {
 		return fmt.Errorf("unknown operation type: %s", tx.Operation.Type)
 	}
+	return handler(tx)
+}
+
+// registerBuiltinOperations wires up the chain's native operation types
+// through the same registry RegisterOperationHandler uses, so plugins and
+// built-ins are dispatched identically.
+func (pac *PersonalAgentChain) registerBuiltinOperations() {
+	pac.operationHandlers["transfer"] = pac.processTransfer
+	pac.operationHandlers["cns_register"] = pac.processCNSRegister
+	pac.operationHandlers["htlc_lock"] = pac.processHTLCLock
+	pac.operationHandlers["htlc_claim"] = pac.processHTLCClaim
+	pac.operationHandlers["htlc_refund"] = pac.processHTLCRefund
+}
+
+// RegisterOperationHandler adds support for a new AgentOperation.Type,
+// letting callers extend processTx without modifying this package. It
+// fails if opType already has a handler, built-in or otherwise, since
+// silently replacing one could change how already-committed blocks would
+// replay.
+func (pac *PersonalAgentChain) RegisterOperationHandler(opType string, handler OperationHandler) error {
+	pac.mu.Lock()
+	defer pac.mu.Unlock()
+
+	if _, exists := pac.operationHandlers[opType]; exists {
+		return fmt.Errorf("operation type %q already has a registered handler", opType)
+	}
+	pac.operationHandlers[opType] = handler
+	return nil
+}
+
+// processHTLCLock escrows value from the sender's balance, redeemable by the
+// recipient with the preimage of the given hash or refundable to the sender
+// after timeout.
+func (pac *PersonalAgentChain) processHTLCLock(tx AgentTransaction) error {
+	hashHex, ok := tx.Operation.Data["hash"].(string)
+	if !ok {
+		return fmt.Errorf("htlc_lock requires a hash")
+	}
+	hashBytes, err := hex.DecodeString(hashHex)
+	if err != nil || len(hashBytes) != 32 {
+		return fmt.Errorf("htlc_lock hash must be 32 bytes hex-encoded")
+	}
+	var hash [32]byte
+	copy(hash[:], hashBytes)
+
+	if _, exists := pac.state.HTLCLocks[hash]; exists {
+		return fmt.Errorf("htlc lock %x already exists", hash)
+	}
+
+	timeoutUnix, ok := tx.Operation.Data["timeout_unix"].(float64)
+	if !ok {
+		return fmt.Errorf("htlc_lock requires timeout_unix")
+	}
+
+	if pac.state.Balance < tx.Value {
+		return fmt.Errorf("insufficient balance to lock %d", tx.Value)
+	}
+	pac.state.Balance -= tx.Value
+
+	pac.state.HTLCLocks[hash] = HTLCLock{
+		Hash:      hash,
+		Amount:    tx.Value,
+		Locker:    tx.From,
+		Recipient: tx.To,
+		Timeout:   time.Unix(int64(timeoutUnix), 0),
+	}
+	return nil
+}
+
+// processHTLCClaim releases a htlc_lock's escrowed value once the correct
+// preimage of the lock's hash is revealed. If the claimant is this chain's
+// own owner (a same-chain lock), the value is credited straight to Balance.
+// Otherwise lock.Recipient is a counterparty who has no Balance on this
+// chain, so the value is recorded in Receivables instead; the counterparty
+// settles it into their own chain's spendable Balance with
+// SettleReceivable, which is what makes AtomicSwapHTLC an actual exchange
+// of value rather than a same-amount round trip.
+func (pac *PersonalAgentChain) processHTLCClaim(tx AgentTransaction) error {
+	hashHex, ok := tx.Operation.Data["hash"].(string)
+	if !ok {
+		return fmt.Errorf("htlc_claim requires a hash")
+	}
+	hashBytes, err := hex.DecodeString(hashHex)
+	if err != nil || len(hashBytes) != 32 {
+		return fmt.Errorf("htlc_claim hash must be 32 bytes hex-encoded")
+	}
+	var hash [32]byte
+	copy(hash[:], hashBytes)
+
+	preimageHex, ok := tx.Operation.Data["preimage"].(string)
+	if !ok {
+		return fmt.Errorf("htlc_claim requires a preimage")
+	}
+	preimage, err := hex.DecodeString(preimageHex)
+	if err != nil {
+		return fmt.Errorf("htlc_claim preimage must be hex-encoded")
+	}
+
+	lock, exists := pac.state.HTLCLocks[hash]
+	if !exists {
+		return fmt.Errorf("no htlc lock %x", hash)
+	}
+	if lock.Claimed || lock.Refunded {
+		return fmt.Errorf("htlc lock %x already settled", hash)
+	}
+	if sha256.Sum256(preimage) != hash {
+		return fmt.Errorf("preimage does not match htlc lock %x", hash)
+	}
+
+	lock.Claimed = true
+	pac.state.HTLCLocks[hash] = lock
+	if lock.Recipient == pac.state.Owner {
+		pac.state.Balance += lock.Amount
+	} else {
+		pac.state.Receivables[lock.Recipient] += lock.Amount
+	}
+	return nil
+}
+
+// GetReceivable returns the value claimed on this chain that is owed to
+// addr but not yet settled into addr's own chain.
+func (pac *PersonalAgentChain) GetReceivable(addr AgentAddress) uint64 {
+	pac.mu.RLock()
+	defer pac.mu.RUnlock()
+	return pac.state.Receivables[addr]
+}
+
+// SettleReceivable moves everything escrowChain owes this chain's owner,
+// as recorded by a prior htlc_claim, into this chain's own spendable
+// Balance. It is the second half of a cross-chain HTLC claim: the first
+// half (processHTLCClaim) can only record the debt on the chain holding
+// the escrow, since it has no way to credit a Balance it doesn't own.
+func (pac *PersonalAgentChain) SettleReceivable(escrowChain *PersonalAgentChain) (uint64, error) {
+	pac.mu.Lock()
+	defer pac.mu.Unlock()
+
+	amount, err := escrowChain.clearReceivable(pac.state.Owner)
+	if err != nil {
+		return 0, err
+	}
+	pac.state.Balance += amount
+	return amount, nil
+}
+
+// clearReceivable zeroes out and returns addr's recorded receivable.
+func (pac *PersonalAgentChain) clearReceivable(addr AgentAddress) (uint64, error) {
+	pac.mu.Lock()
+	defer pac.mu.Unlock()
+
+	amount := pac.state.Receivables[addr]
+	if amount == 0 {
+		return 0, fmt.Errorf("no receivable owed to %s", addr)
+	}
+	delete(pac.state.Receivables, addr)
+	return amount, nil
+}
+
+// processHTLCRefund returns a htlc_lock's escrowed value to this chain's own
+// balance (the original locker) once its timeout has passed, provided it was
+// never claimed.
+func (pac *PersonalAgentChain) processHTLCRefund(tx AgentTransaction) error {
+	hashHex, ok := tx.Operation.Data["hash"].(string)
+	if !ok {
+		return fmt.Errorf("htlc_refund requires a hash")
+	}
+	hashBytes, err := hex.DecodeString(hashHex)
+	if err != nil || len(hashBytes) != 32 {
+		return fmt.Errorf("htlc_refund hash must be 32 bytes hex-encoded")
+	}
+	var hash [32]byte
+	copy(hash[:], hashBytes)
+
+	lock, exists := pac.state.HTLCLocks[hash]
+	if !exists {
+		return fmt.Errorf("no htlc lock %x", hash)
+	}
+	if lock.Claimed || lock.Refunded {
+		return fmt.Errorf("htlc lock %x already settled", hash)
+	}
+	if time.Now().Before(lock.Timeout) {
+		return fmt.Errorf("htlc lock %x has not timed out yet", hash)
+	}
+
+	lock.Refunded = true
+	pac.state.HTLCLocks[hash] = lock
+	pac.state.Balance += lock.Amount
+	return nil
+}
+
+// GetHTLCLock returns the htlc lock recorded under hash, if any.
+func (pac *PersonalAgentChain) GetHTLCLock(hash [32]byte) (HTLCLock, bool) {
+	pac.mu.RLock()
+	defer pac.mu.RUnlock()
+	lock, exists := pac.state.HTLCLocks[hash]
+	return lock, exists
 }
 
 func (pac *PersonalAgentChain) processTransfer(tx AgentTransaction) error {
@@ -0,0 +1,55 @@
+/*
+ * docsgen regenerates the gzipped OpenRPC document describing the agent_
+ * JSON-RPC namespace. `make docsgen` writes it to build/openrpc/agent.json.gz;
+ * `make docsgen-check` regenerates it into a scratch file and diffs against
+ * the checked-in copy so CI catches an agent_ method added without a
+ * matching rpc.agentMethodDocs entry.
+ */
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/credits/alt-ledger-2030/agent/rpc"
+)
+
+func main() {
+	outPath := "build/openrpc/agent.json.gz"
+	if len(os.Args) > 1 {
+		outPath = os.Args[1]
+	}
+
+	data, err := json.MarshalIndent(rpc.BuildDocument(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal OpenRPC document: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create output dir for %s: %v\n", outPath, err)
+		os.Exit(1)
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create %s: %v\n", outPath, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write(data); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", outPath, err)
+		os.Exit(1)
+	}
+	if err := gz.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to finalize %s: %v\n", outPath, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("wrote %s\n", outPath)
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// reservedPrecompileAddress derives a fixed AgentAddress for a built-in
+// precompile from a human-readable label, so reserved addresses don't
+// collide with generated agent addresses (which derive from an owner's
+// public key) without needing a registry of magic constants.
+func reservedPrecompileAddress(label string) AgentAddress {
+	return AgentAddress(sha256.Sum256([]byte("agent-chain:precompile:" + label)))
+}
+
+// CNSPrecompileAddress is the reserved address the CNS precompile answers
+// to.
+var CNSPrecompileAddress = reservedPrecompileAddress("cns")
+
+// CNSRecord is what the CNS precompile stores for one registered name.
+type CNSRecord struct {
+	Owner AgentAddress `json:"owner"`
+	Relay string       `json:"relay"`
+}
+
+// CNSPrecompile is the on-chain replacement for the CLI's old
+// simulated register-cns/resolve-cns behavior: "register" and "resolve"
+// calls read and write CNSRecords through a transaction's StateDB instead
+// of just printing what they would have done.
+type CNSPrecompile struct{}
+
+// Address implements PrecompileContract.
+func (CNSPrecompile) Address() AgentAddress { return CNSPrecompileAddress }
+
+// RequiredGas implements PrecompileContract.
+func (CNSPrecompile) RequiredGas(method string, args [][]byte) uint64 {
+	switch method {
+	case "register":
+		return 50000
+	case "resolve":
+		return 21000
+	default:
+		return 0
+	}
+}
+
+// Run implements PrecompileContract.
+func (CNSPrecompile) Run(ctx *PrecompileContext, method string, args [][]byte) ([]byte, error) {
+	switch method {
+	case "register":
+		return nil, cnsRegister(ctx, args)
+	case "resolve":
+		return cnsResolve(ctx, args)
+	default:
+		return nil, fmt.Errorf("cns precompile: unknown method %q", method)
+	}
+}
+
+func cnsRegister(ctx *PrecompileContext, args [][]byte) error {
+	if len(args) != 3 {
+		return fmt.Errorf("cns register requires 3 args: namespace, name, relay")
+	}
+	namespace, name, relay := string(args[0]), string(args[1]), string(args[2])
+
+	record := CNSRecord{Owner: ctx.Caller, Relay: relay}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode CNS record: %v", err)
+	}
+	ctx.DB.SetStateData(cnsStateKey(namespace, name), data)
+	return nil
+}
+
+func cnsResolve(ctx *PrecompileContext, args [][]byte) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("cns resolve requires 2 args: namespace, name")
+	}
+	namespace, name := string(args[0]), string(args[1])
+
+	data := ctx.DB.GetStateData(cnsStateKey(namespace, name))
+	if data == nil {
+		return nil, fmt.Errorf("no CNS record for %s/%s", namespace, name)
+	}
+	return data, nil
+}
+
+// cnsStateKey is the reserved StateData key a namespace/name pair's
+// CNSRecord is stored under. The leading NUL matches the convention
+// merkle.go's StateKey* constants use, so it can't collide with a key set
+// through SetStateData (those come from Go string literals in caller
+// code).
+func cnsStateKey(namespace, name string) string {
+	return fmt.Sprintf("\x00cns:%s:%s", namespace, name)
+}
@@ -0,0 +1,62 @@
+package conformance
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// junitTestSuite and junitTestCase are the minimal JUnit XML shapes CI
+// tooling (and most test-result viewers) already know how to parse.
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Detail  string `xml:",chardata"`
+}
+
+// WriteJUnit renders the report as a JUnit XML testsuite, one testcase per
+// vector, so it can be consumed by the same CI tooling that reads `go
+// test`'s own JUnit output.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:  "conformance",
+		Tests: len(r.Results),
+	}
+	for _, res := range r.Results {
+		tc := junitTestCase{Name: res.Name}
+		if !res.Passed {
+			suite.Failures++
+			detail := ""
+			for i, f := range res.Failures {
+				if i > 0 {
+					detail += "\n"
+				}
+				detail += f
+			}
+			tc.Failure = &junitFailure{Message: "conformance vector failed", Detail: detail}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,230 @@
+package conformance
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/credits/alt-ledger-2030/agent"
+	"github.com/credits/alt-ledger-2030/agent/simulated"
+)
+
+// publishedEventTypes are the ChainEvent.Type values a replay listens for,
+// the same set rpc.Hub republishes over WebSocket.
+var publishedEventTypes = []string{
+	"transaction_created",
+	"block_committed",
+	"state_updated",
+	"dag_synced",
+}
+
+// eventsWaitTimeout bounds how long replay waits for emitEvent's handler
+// goroutines to land before reading back what they recorded; emitEvent
+// dispatches every handler asynchronously, so there is no other signal
+// that a given CommitBlock's events have all arrived.
+const eventsWaitTimeout = 500 * time.Millisecond
+
+// replayResult is what replaying a Vector's transactions against a fresh
+// chain actually produced.
+type replayResult struct {
+	chain    *agent.PersonalAgentChain
+	events   []string
+	gasUsed  uint64
+	txErrors []string
+}
+
+// replay creates a fresh in-memory agent chain, submits and commits every
+// one of v.Transactions against it, and returns what happened. It never
+// touches v.PostState/ExpectedEvents/ExpectedGas, so the same function
+// backs both Run (which diffs the result against those fields) and
+// Generate (which fills them in from the result).
+func replay(v Vector) (*replayResult, error) {
+	backend := simulated.NewBackend()
+	chain, err := backend.AddAgent(v.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chain: %v", err)
+	}
+
+	result := &replayResult{chain: chain}
+	var mu sync.Mutex
+	for _, eventType := range publishedEventTypes {
+		chain.AddEventHandler(eventType, func(event agent.ChainEvent) {
+			mu.Lock()
+			result.events = append(result.events, event.Type)
+			mu.Unlock()
+		})
+	}
+
+	for i, txv := range v.Transactions {
+		to, err := decodeAddress(txv.To)
+		if err != nil {
+			result.txErrors = append(result.txErrors, fmt.Sprintf("transaction %d: invalid to address: %v", i, err))
+			continue
+		}
+		if _, err := chain.CreateTransaction(to, txv.Operation, txv.Value); err != nil {
+			result.txErrors = append(result.txErrors, fmt.Sprintf("transaction %d: %v", i, err))
+			continue
+		}
+		result.gasUsed += txv.Operation.GasLimit
+	}
+
+	if len(v.Transactions) > len(result.txErrors) {
+		if _, err := backend.Commit(v.Name); err != nil {
+			result.txErrors = append(result.txErrors, fmt.Sprintf("commit: %v", err))
+		}
+	}
+
+	waitForEvents(&mu, &result.events, expectedEventCount(v), eventsWaitTimeout)
+	return result, nil
+}
+
+// expectedEventCount lets waitForEvents know how many events to wait for
+// when diffing against a vector; Generate (which has no expectation yet)
+// passes 0, accepting whatever lands within the timeout.
+func expectedEventCount(v Vector) int {
+	return len(v.ExpectedEvents)
+}
+
+// waitForEvents polls events (guarded by mu) until it has at least want
+// entries or timeout elapses, giving emitEvent's asynchronous handler
+// goroutines a chance to land before the caller reads the slice.
+func waitForEvents(mu *sync.Mutex, events *[]string, want int, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for {
+		mu.Lock()
+		got := len(*events)
+		mu.Unlock()
+		if got >= want || time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func decodeAddress(s string) (agent.AgentAddress, error) {
+	var addr agent.AgentAddress
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return addr, err
+	}
+	if len(b) != 32 {
+		return addr, fmt.Errorf("expected 32 bytes, got %d", len(b))
+	}
+	copy(addr[:], b)
+	return addr, nil
+}
+
+// Result is one vector's pass/fail outcome.
+type Result struct {
+	Name     string
+	Passed   bool
+	Failures []string
+}
+
+// Report is the outcome of running a vector corpus.
+type Report struct {
+	Results []Result
+}
+
+// Passed reports whether every vector in the report passed.
+func (r *Report) Passed() bool {
+	for _, res := range r.Results {
+		if !res.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Run replays every vector against its own fresh chain and diffs the
+// result against what it expects.
+func Run(vectors []Vector) (*Report, error) {
+	report := &Report{Results: make([]Result, 0, len(vectors))}
+	for _, v := range vectors {
+		report.Results = append(report.Results, runOne(v))
+	}
+	return report, nil
+}
+
+func runOne(v Vector) Result {
+	result := Result{Name: v.Name, Passed: true}
+	fail := func(format string, args ...interface{}) {
+		result.Passed = false
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+	}
+
+	replayed, err := replay(v)
+	if err != nil {
+		fail("%v", err)
+		return result
+	}
+	for _, txErr := range replayed.txErrors {
+		fail("%s", txErr)
+	}
+
+	state := replayed.chain.GetState()
+	if state.Balance != v.PostState.Balance {
+		fail("balance: got %d, want %d", state.Balance, v.PostState.Balance)
+	}
+	if state.Nonce != v.PostState.Nonce {
+		fail("nonce: got %d, want %d", state.Nonce, v.PostState.Nonce)
+	}
+	for key, wantHex := range v.PostState.StateData {
+		got := hex.EncodeToString(replayed.chain.GetStateData(key))
+		if got != wantHex {
+			fail("state_data[%s]: got %s, want %s", key, got, wantHex)
+		}
+	}
+	if !stringSlicesEqual(replayed.events, v.ExpectedEvents) {
+		fail("events: got %v, want %v", replayed.events, v.ExpectedEvents)
+	}
+	if replayed.gasUsed != v.ExpectedGas {
+		fail("gas: got %d, want %d", replayed.gasUsed, v.ExpectedGas)
+	}
+
+	return result
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Generate replays txs against a fresh chain named name and captures
+// whatever resulted as a new Vector's PostState/ExpectedEvents/ExpectedGas,
+// for pinning as a regression vector. It fails if any transaction itself
+// failed to replay, since there would be nothing meaningful to pin.
+func Generate(name string, txs []TxVector) (*Vector, error) {
+	v := Vector{Name: name, Transactions: txs}
+
+	replayed, err := replay(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(replayed.txErrors) > 0 {
+		return nil, fmt.Errorf("cannot generate a vector from a failing replay: %s", replayed.txErrors[0])
+	}
+
+	state := replayed.chain.GetState()
+	stateData := make(map[string]string, len(state.StateData))
+	for key, value := range state.StateData {
+		stateData[key] = hex.EncodeToString(value)
+	}
+
+	v.PostState = StateVector{
+		Balance:   state.Balance,
+		Nonce:     state.Nonce,
+		StateData: stateData,
+	}
+	v.ExpectedEvents = replayed.events
+	v.ExpectedGas = replayed.gasUsed
+	return &v, nil
+}
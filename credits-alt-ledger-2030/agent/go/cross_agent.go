@@ -8,6 +8,7 @@ package agent
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
@@ -43,6 +44,10 @@ const (
 	StatusAborting  TransactionStatus = "aborting"
 	StatusAborted   TransactionStatus = "aborted"
 	StatusFailed    TransactionStatus = "failed"
+	// StatusUnknown is returned by TerminationProtocol/Recover when a party
+	// has no record of the transaction at all. Under presumed-abort, a
+	// stranded participant must treat Unknown the same as Aborted.
+	StatusUnknown   TransactionStatus = "unknown"
 )
 
 // PrepareResponse represents a participant's response to the prepare phase
@@ -68,6 +73,28 @@ type CrossAgentCoordinator struct {
 	agentChains       map[AgentAddress]*PersonalAgentChain
 	networkClient     NetworkClient
 	timeoutDuration   time.Duration
+	journal           TxJournal
+
+	// localVotes tracks, per transaction, the prepare vote each locally
+	// hosted participant has cast. A participant that voted no removes its
+	// own entry immediately (presumed-abort) rather than waiting for an
+	// abort message that may never come.
+	localVotes map[CrossAgentTransactionID]map[AgentAddress]TransactionStatus
+
+	metrics CoordinatorMetrics
+}
+
+// CoordinatorMetrics surfaces operational health of the 2PC coordinator.
+type CoordinatorMetrics struct {
+	StuckTransactions    int
+	MeanPrepareLatency   time.Duration
+	MeanCommitLatency    time.Duration
+	ParticipantTimeouts  map[AgentAddress]uint64
+
+	prepareLatencySum time.Duration
+	prepareSamples    uint64
+	commitLatencySum  time.Duration
+	commitSamples     uint64
 }
 
 // NetworkClient interface for communicating with other agents
@@ -75,16 +102,107 @@ type NetworkClient interface {
 	SendPrepareRequest(ctx context.Context, participant AgentAddress, operation AgentOperation) (*PrepareResponse, error)
 	SendCommitRequest(ctx context.Context, participant AgentAddress, txID CrossAgentTransactionID) (*CommitResponse, error)
 	SendAbortRequest(ctx context.Context, participant AgentAddress, txID CrossAgentTransactionID) error
+	// TerminationProtocol lets a participant stuck after prepare poll a peer
+	// (or the coordinator) for the final decision instead of blocking forever.
+	TerminationProtocol(ctx context.Context, peer AgentAddress, txID CrossAgentTransactionID) (TransactionStatus, error)
 }
 
-// NewCrossAgentCoordinator creates a new cross-agent coordinator
-func NewCrossAgentCoordinator(networkClient NetworkClient) *CrossAgentCoordinator {
-	return &CrossAgentCoordinator{
+// TxJournal persists every state transition of a CrossAgentOperation,
+// together with its full payload, before the corresponding phase message is
+// sent. It is the durability boundary the coordinator recovers from after a
+// crash; see the agent/journal package for the default file-backed
+// implementation.
+type TxJournal interface {
+	Append(op CrossAgentOperation) error
+	Load() ([]CrossAgentOperation, error)
+	Close() error
+}
+
+// NewCrossAgentCoordinator creates a new cross-agent coordinator. journal may
+// be nil, in which case the coordinator keeps no durable record of in-flight
+// transactions and cannot recover them after a crash.
+func NewCrossAgentCoordinator(networkClient NetworkClient, journal TxJournal) *CrossAgentCoordinator {
+	cac := &CrossAgentCoordinator{
 		activeTransactions: make(map[CrossAgentTransactionID]*CrossAgentOperation),
 		agentChains:       make(map[AgentAddress]*PersonalAgentChain),
 		networkClient:     networkClient,
 		timeoutDuration:   30 * time.Second,
+		journal:           journal,
+		localVotes:        make(map[CrossAgentTransactionID]map[AgentAddress]TransactionStatus),
+		metrics:           CoordinatorMetrics{ParticipantTimeouts: make(map[AgentAddress]uint64)},
+	}
+
+	if journal != nil {
+		if recovered, err := journal.Load(); err == nil {
+			for i := range recovered {
+				op := recovered[i]
+				cac.activeTransactions[op.ID] = &op
+			}
+		} else {
+			fmt.Printf("Failed to load tx journal: %v\n", err)
+		}
 	}
+
+	return cac
+}
+
+// Recover re-drives every transaction the coordinator loaded from the
+// journal at startup: prepared-but-not-committed transactions are re-driven
+// to commit, and anything not yet prepared is aborted. It should be called
+// once all participant agent chains have been registered.
+func (cac *CrossAgentCoordinator) Recover(ctx context.Context) {
+	cac.mu.Lock()
+	stuck := make([]*CrossAgentOperation, 0, len(cac.activeTransactions))
+	for _, op := range cac.activeTransactions {
+		stuck = append(stuck, op)
+	}
+	cac.mu.Unlock()
+
+	for _, op := range stuck {
+		switch op.Status {
+		case StatusPrepared, StatusCommitting:
+			if cac.commitPhase(ctx, op) {
+				op.Status = StatusCommitted
+				cac.journalAppend(*op)
+			} else {
+				op.Status = StatusFailed
+				cac.journalAppend(*op)
+			}
+		case StatusCommitted:
+			// Already decided; nothing left to drive.
+		default:
+			cac.abortPhase(ctx, op)
+			op.Status = StatusAborted
+			cac.journalAppend(*op)
+		}
+
+		cac.mu.Lock()
+		delete(cac.activeTransactions, op.ID)
+		cac.mu.Unlock()
+	}
+}
+
+func (cac *CrossAgentCoordinator) journalAppend(op CrossAgentOperation) {
+	if cac.journal == nil {
+		return
+	}
+	if err := cac.journal.Append(op); err != nil {
+		fmt.Printf("Failed to append tx journal entry for %s: %v\n", op.ID, err)
+	}
+}
+
+// HandleTerminationProtocol answers a peer's query about the final decision
+// for txID: Committed, Aborted, or Unknown if this coordinator (or one of
+// its locally hosted participants) has already forgotten the transaction.
+// By presumed-abort semantics, the caller must treat Unknown as Aborted.
+func (cac *CrossAgentCoordinator) HandleTerminationProtocol(txID CrossAgentTransactionID, participant AgentAddress) TransactionStatus {
+	cac.mu.RLock()
+	op, exists := cac.activeTransactions[txID]
+	cac.mu.RUnlock()
+	if exists {
+		return op.Status
+	}
+	return cac.localVoteStatus(txID, participant)
 }
 
 // RegisterAgentChain registers an agent chain with the coordinator
@@ -202,6 +320,165 @@ func (cac *CrossAgentCoordinator) AtomicSwap(
 	return err
 }
 
+// AtomicSwapHTLC performs a coordinator-free atomic swap between two agent
+// chains using a hashed-timelock-contract flow, so neither side needs to
+// trust a coordinator or be online for both legs at once. agent1 picks the
+// secret, so it must reveal first; agent2's lock uses a strictly shorter
+// timeout so agent1 cannot wait out agent2's refund window before claiming.
+//
+// A claim can only record what the escrow chain owes the claimant (see
+// processHTLCClaim), since a chain has no way to credit Balance it doesn't
+// own; each side's claim is therefore followed by a SettleReceivable call
+// that moves the recorded debt into the claimant's own chain, completing
+// the actual exchange of value.
+func (cac *CrossAgentCoordinator) AtomicSwapHTLC(
+	agent1, agent2 AgentAddress,
+	amount1, amount2 uint64,
+	timeout1, timeout2 time.Duration,
+) error {
+	if timeout2 >= timeout1 {
+		return fmt.Errorf("agent2's timeout must be strictly shorter than agent1's")
+	}
+
+	cac.mu.RLock()
+	chain1, ok1 := cac.agentChains[agent1]
+	chain2, ok2 := cac.agentChains[agent2]
+	cac.mu.RUnlock()
+	if !ok1 || !ok2 {
+		return fmt.Errorf("both agents must be registered with the coordinator")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate htlc secret: %v", err)
+	}
+	hash := sha256.Sum256(secret)
+	hashHex := hex.EncodeToString(hash[:])
+
+	if err := lockHTLC(chain1, agent2, amount1, hashHex, timeout1); err != nil {
+		return fmt.Errorf("agent1 lock failed: %v", err)
+	}
+
+	if _, exists := chain1.GetHTLCLock(hash); !exists {
+		return fmt.Errorf("agent1 lock did not land on its chain")
+	}
+
+	if err := lockHTLC(chain2, agent1, amount2, hashHex, timeout2); err != nil {
+		return fmt.Errorf("agent2 lock failed: %v", err)
+	}
+
+	// agent1 reveals the secret by claiming its counter-lock on agent2's
+	// chain, which is the only place the preimage needs to be published.
+	if err := claimHTLC(chain2, agent1, hashHex, hex.EncodeToString(secret)); err != nil {
+		return fmt.Errorf("agent1 claim on agent2's chain failed: %v", err)
+	}
+
+	// agent2 observes the now-public preimage and claims on agent1's chain.
+	if err := claimHTLC(chain1, agent2, hashHex, hex.EncodeToString(secret)); err != nil {
+		return fmt.Errorf("agent2 claim on agent1's chain failed: %v", err)
+	}
+
+	// Each claim above only recorded a receivable on the escrow chain;
+	// settle both into the claimant's own spendable Balance to actually
+	// move value across.
+	if _, err := chain1.SettleReceivable(chain2); err != nil {
+		return fmt.Errorf("failed to settle agent1's receivable: %v", err)
+	}
+	if _, err := chain2.SettleReceivable(chain1); err != nil {
+		return fmt.Errorf("failed to settle agent2's receivable: %v", err)
+	}
+
+	return nil
+}
+
+func lockHTLC(chain *PersonalAgentChain, recipient AgentAddress, amount uint64, hashHex string, timeout time.Duration) error {
+	operation := AgentOperation{
+		Type: "htlc_lock",
+		Data: map[string]interface{}{
+			"hash":         hashHex,
+			"timeout_unix": float64(time.Now().Add(timeout).Unix()),
+		},
+		Nonce:    chain.GetState().Nonce + 1,
+		GasLimit: 42000,
+	}
+	if _, err := chain.CreateTransaction(recipient, operation, amount); err != nil {
+		return err
+	}
+	_, err := chain.CommitBlock()
+	return err
+}
+
+func claimHTLC(chain *PersonalAgentChain, claimant AgentAddress, hashHex, preimageHex string) error {
+	operation := AgentOperation{
+		Type: "htlc_claim",
+		Data: map[string]interface{}{
+			"hash":     hashHex,
+			"preimage": preimageHex,
+		},
+		Nonce:    chain.GetState().Nonce + 1,
+		GasLimit: 42000,
+	}
+	if _, err := chain.CreateTransaction(claimant, operation, 0); err != nil {
+		return err
+	}
+	_, err := chain.CommitBlock()
+	return err
+}
+
+// StartHTLCWatcher periodically scans every registered agent chain for
+// timed-out, unclaimed HTLC locks and automatically submits the refund on
+// behalf of the locker. It returns a function that stops the watcher.
+func (cac *CrossAgentCoordinator) StartHTLCWatcher(interval time.Duration) func() {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cac.refundExpiredHTLCs()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (cac *CrossAgentCoordinator) refundExpiredHTLCs() {
+	cac.mu.RLock()
+	chains := make([]*PersonalAgentChain, 0, len(cac.agentChains))
+	for _, chain := range cac.agentChains {
+		chains = append(chains, chain)
+	}
+	cac.mu.RUnlock()
+
+	for _, chain := range chains {
+		for hash, lock := range chain.GetState().HTLCLocks {
+			if lock.Claimed || lock.Refunded || time.Now().Before(lock.Timeout) {
+				continue
+			}
+			operation := AgentOperation{
+				Type: "htlc_refund",
+				Data: map[string]interface{}{
+					"hash": hex.EncodeToString(hash[:]),
+				},
+				Nonce:    chain.GetState().Nonce + 1,
+				GasLimit: 21000,
+			}
+			if _, err := chain.CreateTransaction(lock.Locker, operation, 0); err != nil {
+				fmt.Printf("Failed to create htlc refund for lock %x: %v\n", hash, err)
+				continue
+			}
+			if _, err := chain.CommitBlock(); err != nil {
+				fmt.Printf("Failed to commit htlc refund for lock %x: %v\n", hash, err)
+			}
+		}
+	}
+}
+
 // GetActiveTransactions returns all active cross-agent transactions
 func (cac *CrossAgentCoordinator) GetActiveTransactions() []CrossAgentOperation {
 	cac.mu.RLock()
@@ -235,38 +512,47 @@ func (cac *CrossAgentCoordinator) executeTwoPhaseCommit(crossTx *CrossAgentOpera
 	
 	// Phase 1: Prepare
 	crossTx.Status = StatusPreparing
+	cac.journalAppend(*crossTx)
 	if !cac.preparePhase(ctx, crossTx) {
 		crossTx.Status = StatusAborting
 		cac.abortPhase(ctx, crossTx)
 		crossTx.Status = StatusAborted
+		cac.journalAppend(*crossTx)
 		return fmt.Errorf("prepare phase failed")
 	}
-	
+
 	crossTx.Status = StatusPrepared
-	
-	// Phase 2: Commit
+	cac.journalAppend(*crossTx)
+
+	// Phase 2: Commit. Only the commit decision needs to be force-logged
+	// before the commit messages go out - presumed-abort means a crash
+	// before this point recovers safely as an abort.
 	crossTx.Status = StatusCommitting
+	cac.journalAppend(*crossTx)
 	if !cac.commitPhase(ctx, crossTx) {
 		crossTx.Status = StatusFailed
+		cac.journalAppend(*crossTx)
 		return fmt.Errorf("commit phase failed")
 	}
-	
+
 	crossTx.Status = StatusCommitted
+	cac.journalAppend(*crossTx)
 	return nil
 }
 
 func (cac *CrossAgentCoordinator) preparePhase(ctx context.Context, crossTx *CrossAgentOperation) bool {
+	start := time.Now()
 	prepareResponses := make(chan PrepareResponse, len(crossTx.Participants))
-	
+
 	// Send prepare requests to all participants
 	for _, participant := range crossTx.Participants {
 		go func(p AgentAddress) {
 			operation := crossTx.Operations[p]
-			
+
 			// Check if this is a local agent chain
 			if chain, exists := cac.agentChains[p]; exists {
 				// Local prepare
-				response := cac.prepareLocal(chain, operation)
+				response := cac.prepareLocal(chain, crossTx.ID, operation)
 				prepareResponses <- response
 			} else {
 				// Remote prepare
@@ -284,7 +570,7 @@ func (cac *CrossAgentCoordinator) preparePhase(ctx context.Context, crossTx *Cro
 			}
 		}(participant)
 	}
-	
+
 	// Collect responses
 	successCount := 0
 	for i := 0; i < len(crossTx.Participants); i++ {
@@ -297,15 +583,62 @@ func (cac *CrossAgentCoordinator) preparePhase(ctx context.Context, crossTx *Cro
 			}
 		case <-ctx.Done():
 			fmt.Println("Prepare phase timeout")
+			cac.recordTimeouts(crossTx.Participants)
 			return false
 		}
 	}
-	
+
+	cac.recordPrepareLatency(time.Since(start))
+
 	// All participants must be prepared
 	return successCount == len(crossTx.Participants)
 }
 
+func (cac *CrossAgentCoordinator) recordPrepareLatency(d time.Duration) {
+	cac.mu.Lock()
+	defer cac.mu.Unlock()
+	cac.metrics.prepareLatencySum += d
+	cac.metrics.prepareSamples++
+}
+
+func (cac *CrossAgentCoordinator) recordCommitLatency(d time.Duration) {
+	cac.mu.Lock()
+	defer cac.mu.Unlock()
+	cac.metrics.commitLatencySum += d
+	cac.metrics.commitSamples++
+}
+
+func (cac *CrossAgentCoordinator) recordTimeouts(participants []AgentAddress) {
+	cac.mu.Lock()
+	defer cac.mu.Unlock()
+	for _, p := range participants {
+		cac.metrics.ParticipantTimeouts[p]++
+	}
+}
+
+// GetMetrics returns a snapshot of the coordinator's operational metrics.
+func (cac *CrossAgentCoordinator) GetMetrics() CoordinatorMetrics {
+	cac.mu.RLock()
+	defer cac.mu.RUnlock()
+
+	snapshot := CoordinatorMetrics{
+		StuckTransactions:   len(cac.activeTransactions),
+		ParticipantTimeouts: make(map[AgentAddress]uint64, len(cac.metrics.ParticipantTimeouts)),
+	}
+	for p, n := range cac.metrics.ParticipantTimeouts {
+		snapshot.ParticipantTimeouts[p] = n
+	}
+	if cac.metrics.prepareSamples > 0 {
+		snapshot.MeanPrepareLatency = cac.metrics.prepareLatencySum / time.Duration(cac.metrics.prepareSamples)
+	}
+	if cac.metrics.commitSamples > 0 {
+		snapshot.MeanCommitLatency = cac.metrics.commitLatencySum / time.Duration(cac.metrics.commitSamples)
+	}
+	return snapshot
+}
+
 func (cac *CrossAgentCoordinator) commitPhase(ctx context.Context, crossTx *CrossAgentOperation) bool {
+	start := time.Now()
 	commitResponses := make(chan CommitResponse, len(crossTx.Participants))
 	
 	// Send commit requests to all participants
@@ -345,10 +678,13 @@ func (cac *CrossAgentCoordinator) commitPhase(ctx context.Context, crossTx *Cros
 			}
 		case <-ctx.Done():
 			fmt.Println("Commit phase timeout")
+			cac.recordTimeouts(crossTx.Participants)
 			return false
 		}
 	}
-	
+
+	cac.recordCommitLatency(time.Since(start))
+
 	// All participants must commit successfully
 	return successCount == len(crossTx.Participants)
 }
@@ -369,44 +705,70 @@ func (cac *CrossAgentCoordinator) abortPhase(ctx context.Context, crossTx *Cross
 	}
 }
 
-func (cac *CrossAgentCoordinator) prepareLocal(chain *PersonalAgentChain, operation AgentOperation) PrepareResponse {
+func (cac *CrossAgentCoordinator) prepareLocal(chain *PersonalAgentChain, txID CrossAgentTransactionID, operation AgentOperation) PrepareResponse {
 	// Validate that the operation can be executed
 	state := chain.GetState()
-	
+
+	var response PrepareResponse
 	switch operation.Type {
 	case "atomic_transfer_debit":
-		if amount, ok := operation.Data["amount"].(float64); ok {
-			if state.Balance >= uint64(amount) {
-				return PrepareResponse{
-					Participant: state.Owner,
-					Success:     true,
-					Timestamp:   time.Now(),
-				}
-			}
+		if amount, ok := operation.Data["amount"].(float64); ok && state.Balance >= uint64(amount) {
+			response = PrepareResponse{Participant: state.Owner, Success: true, Timestamp: time.Now()}
+		} else {
+			response = PrepareResponse{Participant: state.Owner, Success: false, Error: "insufficient balance", Timestamp: time.Now()}
 		}
-		return PrepareResponse{
-			Participant: state.Owner,
-			Success:     false,
-			Error:       "insufficient balance",
-			Timestamp:   time.Now(),
-		}
-		
+
 	case "atomic_transfer_credit", "atomic_swap":
 		// These operations don't require pre-validation
-		return PrepareResponse{
-			Participant: state.Owner,
-			Success:     true,
-			Timestamp:   time.Now(),
-		}
-		
+		response = PrepareResponse{Participant: state.Owner, Success: true, Timestamp: time.Now()}
+
 	default:
-		return PrepareResponse{
-			Participant: state.Owner,
-			Success:     false,
-			Error:       "unknown operation type",
-			Timestamp:   time.Now(),
-		}
+		response = PrepareResponse{Participant: state.Owner, Success: false, Error: "unknown operation type", Timestamp: time.Now()}
 	}
+
+	// Persist the vote before responding, so commitLocal/abortLocal can find
+	// it even if this goroutine's response never reaches the coordinator.
+	if response.Success {
+		cac.recordVote(txID, state.Owner, StatusPrepared)
+	} else {
+		// Presumed-abort: a "no" vote never needs to be driven to Aborted by
+		// a message from the coordinator - the participant can forget the
+		// transaction immediately.
+		cac.forgetVote(txID, state.Owner)
+	}
+
+	return response
+}
+
+func (cac *CrossAgentCoordinator) recordVote(txID CrossAgentTransactionID, participant AgentAddress, status TransactionStatus) {
+	cac.mu.Lock()
+	defer cac.mu.Unlock()
+	if cac.localVotes[txID] == nil {
+		cac.localVotes[txID] = make(map[AgentAddress]TransactionStatus)
+	}
+	cac.localVotes[txID][participant] = status
+}
+
+func (cac *CrossAgentCoordinator) forgetVote(txID CrossAgentTransactionID, participant AgentAddress) {
+	cac.mu.Lock()
+	defer cac.mu.Unlock()
+	delete(cac.localVotes[txID], participant)
+	if len(cac.localVotes[txID]) == 0 {
+		delete(cac.localVotes, txID)
+	}
+}
+
+// localVoteStatus returns what a locally hosted participant currently
+// remembers about txID: Prepared if it voted yes and hasn't settled yet, or
+// Unknown if it never voted, voted no, or has already settled and forgotten
+// the transaction.
+func (cac *CrossAgentCoordinator) localVoteStatus(txID CrossAgentTransactionID, participant AgentAddress) TransactionStatus {
+	cac.mu.RLock()
+	defer cac.mu.RUnlock()
+	if status, ok := cac.localVotes[txID][participant]; ok {
+		return status
+	}
+	return StatusUnknown
 }
 
 func (cac *CrossAgentCoordinator) commitLocal(chain *PersonalAgentChain, txID CrossAgentTransactionID) CommitResponse {
@@ -448,7 +810,9 @@ func (cac *CrossAgentCoordinator) commitLocal(chain *PersonalAgentChain, txID Cr
 			Timestamp:   time.Now(),
 		}
 	}
-	
+
+	cac.forgetVote(txID, state.Owner)
+
 	return CommitResponse{
 		Participant: state.Owner,
 		Success:     true,
@@ -459,6 +823,7 @@ func (cac *CrossAgentCoordinator) commitLocal(chain *PersonalAgentChain, txID Cr
 func (cac *CrossAgentCoordinator) abortLocal(chain *PersonalAgentChain, txID CrossAgentTransactionID) {
 	// Local abort - just clean up any prepared state
 	// In this simplified implementation, we don't maintain prepared state
+	cac.forgetVote(txID, chain.GetState().Owner)
 	fmt.Printf("Aborting transaction %x for local chain %s\n", txID, chain.GetState().Owner)
 }
 
@@ -0,0 +1,133 @@
+package agent
+
+import "fmt"
+
+// History returns committed transactions matching filter, most recently
+// committed first. It requires the chain's Store to implement HistoryIndex
+// (store.BoltStore does; the in-memory stores don't, since there's nothing
+// to persist across a restart to query in the first place).
+func (pac *PersonalAgentChain) History(filter HistoryFilter) ([]AgentTransaction, error) {
+	pac.mu.Lock()
+	store := pac.store
+	pac.mu.Unlock()
+
+	hi, ok := store.(HistoryIndex)
+	if !ok {
+		return nil, fmt.Errorf("history requires a Store that implements HistoryIndex (e.g. store.BoltStore)")
+	}
+
+	// The index query only narrows by whichever single field it's keyed
+	// on; Limit only applies there if no other filter field still needs to
+	// reject candidates afterward, and Since always does.
+	indexLimit := filter.Limit
+	narrowedFields := 0
+	for _, set := range []bool{filter.Op != "", filter.From != nil, filter.To != nil, filter.Since != 0} {
+		if set {
+			narrowedFields++
+		}
+	}
+	if narrowedFields > 1 {
+		indexLimit = 0
+	}
+
+	var candidates []TransactionHash
+	var err error
+	switch {
+	case filter.Op != "":
+		candidates, err = hi.QueryHistoryByOp(filter.Op, indexLimit)
+	case filter.From != nil:
+		candidates, err = hi.QueryHistoryByFrom(*filter.From, indexLimit)
+	case filter.To != nil:
+		candidates, err = hi.QueryHistoryByTo(*filter.To, indexLimit)
+	default:
+		candidates, err = hi.QueryHistoryAll(indexLimit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history index: %v", err)
+	}
+
+	results := make([]AgentTransaction, 0, len(candidates))
+	for _, txID := range candidates {
+		tx, err := pac.GetTransaction(txID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load indexed transaction %x: %v", txID, err)
+		}
+		if filter.From != nil && tx.From != *filter.From {
+			continue
+		}
+		if filter.To != nil && tx.To != *filter.To {
+			continue
+		}
+		if filter.Op != "" && tx.Operation.Type != filter.Op {
+			continue
+		}
+		if filter.Since != 0 && tx.Timestamp < filter.Since {
+			continue
+		}
+		results = append(results, *tx)
+		if filter.Limit > 0 && len(results) >= filter.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// Replay reconstructs AgentChainState by replaying every committed block
+// from genesis through an ephemeral chain sharing this chain's signer and
+// operation/precompile handlers, rather than trusting the persisted state
+// snapshot. fromHeight is the height Replay reports it started verifying
+// from; state itself always rebuilds from genesis, since the Store only
+// keeps the latest state snapshot, not one per height. Used to recover a
+// chain's state from its block log alone, and to sanity-check that the
+// persisted snapshot matches what replaying every block actually produces.
+func (pac *PersonalAgentChain) Replay(fromHeight uint64) (*AgentChainState, error) {
+	pac.mu.Lock()
+	targetHeight := pac.state.Height
+	owner := pac.state.Owner
+	chainID := pac.state.ChainID
+	pac.mu.Unlock()
+
+	if fromHeight > targetHeight {
+		return nil, fmt.Errorf("fromHeight %d is beyond current height %d", fromHeight, targetHeight)
+	}
+
+	replay := &PersonalAgentChain{
+		signer: pac.signer,
+		store:  newMemStore(),
+		state: AgentChainState{
+			Owner:     owner,
+			ChainID:   chainID,
+			StateData: make(map[string][]byte),
+			HTLCLocks: make(map[[32]byte]HTLCLock),
+		},
+		stateTree:     NewMPT(),
+		eventHandlers: make(map[string][]EventHandler),
+		maxBlockSize:  defaultMaxBlockSize,
+		maxBlockGas:   defaultMaxBlockGas,
+	}
+	replay.syncStateTree()
+	replay.operationHandlers = make(map[string]OperationHandler)
+	replay.registerBuiltinOperations()
+	replay.precompiles = NewPrecompileRegistry()
+	if err := replay.precompiles.Register(CNSPrecompile{}); err != nil {
+		return nil, fmt.Errorf("failed to register CNS precompile for replay: %v", err)
+	}
+
+	for height := uint64(1); height <= targetHeight; height++ {
+		block, err := pac.GetBlockByHeight(height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load block %d for replay: %v", height, err)
+		}
+		for _, tx := range block.Transactions {
+			if err := replay.processTx(tx); err != nil {
+				return nil, fmt.Errorf("failed to replay transaction %x in block %d: %v", tx.ID, height, err)
+			}
+		}
+		replay.state.Height = block.Height
+		replay.state.LastBlockHash = block.Hash
+		replay.state.Nonce++
+	}
+
+	state := replay.state
+	return &state, nil
+}
@@ -0,0 +1,199 @@
+/*
+ * Write-ahead journal for the cross-agent 2PC coordinator
+ *
+ * BoltJournal is the default durable implementation of agent.TxJournal,
+ * backed by the same bbolt dependency the Store package already uses.
+ * FileJournal is a lighter, dependency-free append-only log for callers
+ * that would rather not open a bbolt file; MemJournal has no durability at
+ * all and exists for tests.
+ */
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/credits/alt-ledger-2030/agent"
+)
+
+// journalBucket is the single bbolt bucket BoltJournal keeps every entry in,
+// keyed by CrossAgentTransactionID.
+var journalBucket = []byte("journal")
+
+// BoltJournal is a bbolt-backed agent.TxJournal: every Append is a durable,
+// fsync'd transaction keyed by the operation's ID, so Load can replay the
+// most recent state transition recorded for each cross-agent transaction.
+type BoltJournal struct {
+	db *bbolt.DB
+}
+
+// NewBoltJournal opens (or creates) a bbolt-backed journal at path.
+func NewBoltJournal(path string) (*BoltJournal, error) {
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal %s: %v", path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(journalBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize journal bucket in %s: %v", path, err)
+	}
+	return &BoltJournal{db: db}, nil
+}
+
+// Append persists one state transition of op before the coordinator is
+// allowed to send the corresponding phase message.
+func (j *BoltJournal) Append(op agent.CrossAgentOperation) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %v", err)
+	}
+	return j.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(journalBucket).Put(op.ID[:], data)
+	})
+}
+
+// Load replays the journal and returns the most recent recorded transition
+// for every transaction it has ever seen, in no particular order.
+func (j *BoltJournal) Load() ([]agent.CrossAgentOperation, error) {
+	var ops []agent.CrossAgentOperation
+	err := j.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(journalBucket).ForEach(func(k, v []byte) error {
+			var op agent.CrossAgentOperation
+			if err := json.Unmarshal(v, &op); err != nil {
+				return fmt.Errorf("failed to unmarshal journal entry: %v", err)
+			}
+			ops = append(ops, op)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// Close releases the underlying bbolt database handle.
+func (j *BoltJournal) Close() error {
+	return j.db.Close()
+}
+
+// FileJournal is an append-only, fsync'd journal of CrossAgentOperation
+// state transitions, for callers that want crash durability without the
+// bbolt dependency BoltJournal pulls in. Every call to Append writes one
+// JSON line and fsyncs before returning, so a transition is never reported
+// as durable until it actually is on disk.
+type FileJournal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileJournal opens (or creates) the journal file at path for appending.
+func NewFileJournal(path string) (*FileJournal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal %s: %v", path, err)
+	}
+	return &FileJournal{file: f}, nil
+}
+
+// Append persists one state transition of op before the coordinator is
+// allowed to send the corresponding phase message.
+func (j *FileJournal) Append(op agent.CrossAgentOperation) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	line, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %v", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := j.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write journal entry: %v", err)
+	}
+	return j.file.Sync()
+}
+
+// Load replays the journal and returns the most recent recorded transition
+// for every transaction it has ever seen, in no particular order.
+func (j *FileJournal) Load() ([]agent.CrossAgentOperation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("failed to rewind journal: %v", err)
+	}
+
+	latest := make(map[agent.CrossAgentTransactionID]agent.CrossAgentOperation)
+	scanner := bufio.NewScanner(j.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var op agent.CrossAgentOperation
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			continue // tolerate a torn trailing write from a mid-append crash
+		}
+		latest[op.ID] = op
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan journal: %v", err)
+	}
+
+	if _, err := j.file.Seek(0, 2); err != nil {
+		return nil, fmt.Errorf("failed to seek to end of journal: %v", err)
+	}
+
+	ops := make([]agent.CrossAgentOperation, 0, len(latest))
+	for _, op := range latest {
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// Close releases the underlying file handle.
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// MemJournal is an in-memory TxJournal, useful for tests and for running a
+// coordinator with no crash-recovery guarantees.
+type MemJournal struct {
+	mu  sync.Mutex
+	ops map[agent.CrossAgentTransactionID]agent.CrossAgentOperation
+}
+
+// NewMemJournal creates an empty in-memory journal.
+func NewMemJournal() *MemJournal {
+	return &MemJournal{ops: make(map[agent.CrossAgentTransactionID]agent.CrossAgentOperation)}
+}
+
+func (j *MemJournal) Append(op agent.CrossAgentOperation) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.ops[op.ID] = op
+	return nil
+}
+
+func (j *MemJournal) Load() ([]agent.CrossAgentOperation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ops := make([]agent.CrossAgentOperation, 0, len(j.ops))
+	for _, op := range j.ops {
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func (j *MemJournal) Close() error {
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+ * Agent key management and canonical transaction encoding
+ *
+ * Ed25519 is the default signature scheme; Signer is the extension point for
+ * adding secp256k1/BLS variants later without touching the chain code that
+ * consumes signatures.
+ */
+package agent
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// PublicKey is a verifying key. The default implementation wraps an Ed25519
+// public key.
+type PublicKey []byte
+
+// Verify reports whether sig is a valid signature over msg under pub.
+func (pub PublicKey) Verify(msg, sig []byte) bool {
+	return ed25519.Verify(ed25519.PublicKey(pub), msg, sig)
+}
+
+// Address derives the AgentAddress a public key controls: sha256 of the
+// raw public key bytes.
+func (pub PublicKey) Address() AgentAddress {
+	return AgentAddress(sha256.Sum256(pub))
+}
+
+// PrivateKey is a signing key. The default implementation wraps an Ed25519
+// private key.
+type PrivateKey []byte
+
+// Sign produces a signature over msg.
+func (priv PrivateKey) Sign(msg []byte) []byte {
+	return ed25519.Sign(ed25519.PrivateKey(priv), msg)
+}
+
+// Public returns the public key half of priv.
+func (priv PrivateKey) Public() PublicKey {
+	return PublicKey(ed25519.PrivateKey(priv).Public().(ed25519.PublicKey))
+}
+
+// Signer is the extension point for additional signature schemes
+// (secp256k1, BLS, ...) beyond the default Ed25519 KeyPair.
+type Signer interface {
+	Sign(msg []byte) []byte
+	PublicKey() PublicKey
+}
+
+// KeyPair is an agent's signing identity. It implements Signer directly so
+// it can be handed to NewPersonalAgentChain without an adapter.
+type KeyPair struct {
+	Private PrivateKey
+	Public  PublicKey
+}
+
+// Sign implements Signer.
+func (kp *KeyPair) Sign(msg []byte) []byte {
+	return kp.Private.Sign(msg)
+}
+
+// PublicKey implements Signer.
+func (kp *KeyPair) PublicKey() PublicKey {
+	return kp.Public
+}
+
+// Address derives the AgentAddress this key pair controls.
+func (kp *KeyPair) Address() AgentAddress {
+	return kp.Public.Address()
+}
+
+// GenerateKeyPair creates a new random Ed25519 identity.
+func GenerateKeyPair() (*KeyPair, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key pair: %v", err)
+	}
+	return &KeyPair{Private: PrivateKey(priv), Public: PublicKey(pub)}, nil
+}
+
+// encodeTransactionForSigning produces a canonical, deterministic byte
+// encoding of a transaction's signable fields. Unlike json.Marshal on a
+// map[string]interface{}, field order here is fixed and operation data keys
+// are sorted, so two nodes that construct the same logical transaction
+// always hash and sign identical bytes.
+func encodeTransactionForSigning(tx AgentTransaction) []byte {
+	buf := new(bytes.Buffer)
+	buf.Write(tx.From[:])
+	buf.Write(tx.To[:])
+	buf.WriteString(tx.Operation.Type)
+	buf.Write(encodeOperationData(tx.Operation.Data))
+	writeUint64(buf, tx.Operation.Nonce)
+	writeUint64(buf, tx.Operation.GasLimit)
+	writeUint64(buf, tx.Value)
+	writeUint64(buf, tx.Timestamp)
+	return buf.Bytes()
+}
+
+// encodeOperationData renders operation data as sorted "key\x00value\x00"
+// tuples so the encoding does not depend on Go's randomized map iteration
+// order.
+func encodeOperationData(data map[string]interface{}) []byte {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := new(bytes.Buffer)
+	for _, k := range keys {
+		buf.WriteString(k)
+		buf.WriteByte(0)
+		fmt.Fprintf(buf, "%v", data[k])
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	buf.Write(b)
+}
+
+// VerifyTransaction reports whether tx.Signature is a valid signature over
+// tx's canonical encoding under sender's public key.
+func VerifyTransaction(tx AgentTransaction, sender PublicKey) bool {
+	return sender.Verify(encodeTransactionForSigning(tx), tx.Signature)
+}
+
+// VerifyBlock verifies every transaction in block against the public key
+// reported by lookup for that transaction's sender. It fails closed: any
+// transaction whose sender cannot be resolved, or whose signature does not
+// verify, fails the whole block.
+func VerifyBlock(block AgentBlock, lookup func(AgentAddress) (PublicKey, bool)) bool {
+	for _, tx := range block.Transactions {
+		pub, ok := lookup(tx.From)
+		if !ok {
+			return false
+		}
+		if !VerifyTransaction(tx, pub) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+/*
+ * Password-protected keystore file format
+ *
+ * Mirrors the shape of the Ethereum/Neo Go keystore: a password is run
+ * through scrypt to derive a symmetric key, which encrypts the private key
+ * under AES-CTR, with an HMAC-SHA256 MAC over the ciphertext so a wrong
+ * password (or tampering) is detected rather than silently producing a
+ * garbage key.
+ */
+package agent
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	scryptN     = 1 << 18
+	scryptR     = 8
+	scryptP     = 1
+	scryptKeyLen = 32
+	scryptSaltLen = 32
+)
+
+// keystoreJSON is the on-disk representation of an encrypted KeyPair.
+type keystoreJSON struct {
+	Address string       `json:"address"`
+	Crypto  cryptoParams `json:"crypto"`
+	Version int          `json:"version"`
+}
+
+type cryptoParams struct {
+	CipherText string       `json:"ciphertext"`
+	IV         string       `json:"iv"`
+	MAC        string       `json:"mac"`
+	ScryptN    int          `json:"scrypt_n"`
+	ScryptR    int          `json:"scrypt_r"`
+	ScryptP    int          `json:"scrypt_p"`
+	Salt       string       `json:"salt"`
+}
+
+// EncryptKeyPair encrypts kp's private key under password and returns the
+// keystore file contents as JSON.
+func EncryptKeyPair(kp *KeyPair, password string) ([]byte, error) {
+	salt := make([]byte, scryptSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	derivedKey, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("failed to generate iv: %v", err)
+	}
+
+	cipherText := make([]byte, len(kp.Private))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, kp.Private)
+
+	mac := hmac.New(sha256.New, derivedKey[16:32])
+	mac.Write(cipherText)
+	macSum := mac.Sum(nil)
+
+	addr := kp.Address()
+	ks := keystoreJSON{
+		Address: hex.EncodeToString(addr[:]),
+		Version: 1,
+		Crypto: cryptoParams{
+			CipherText: hex.EncodeToString(cipherText),
+			IV:         hex.EncodeToString(iv),
+			MAC:        hex.EncodeToString(macSum),
+			ScryptN:    scryptN,
+			ScryptR:    scryptR,
+			ScryptP:    scryptP,
+			Salt:       hex.EncodeToString(salt),
+		},
+	}
+
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// DecryptKeyPair recovers a KeyPair from keystore file contents given the
+// password it was encrypted with.
+func DecryptKeyPair(data []byte, password string) (*KeyPair, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore file: %v", err)
+	}
+
+	salt, err := hex.DecodeString(ks.Crypto.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %v", err)
+	}
+
+	derivedKey, err := scrypt.Key([]byte(password), salt, ks.Crypto.ScryptN, ks.Crypto.ScryptR, ks.Crypto.ScryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, derivedKey[16:32])
+	mac.Write(cipherText)
+	expectedMAC := mac.Sum(nil)
+
+	gotMAC, err := hex.DecodeString(ks.Crypto.MAC)
+	if err != nil || !hmac.Equal(expectedMAC, gotMAC) {
+		return nil, fmt.Errorf("invalid password or corrupted keystore file")
+	}
+
+	iv, err := hex.DecodeString(ks.Crypto.IV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid iv: %v", err)
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+
+	privKey := make([]byte, len(cipherText))
+	cipher.NewCTR(block, iv).XORKeyStream(privKey, cipherText)
+
+	kp := &KeyPair{Private: PrivateKey(privKey)}
+	kp.Public = kp.Private.Public()
+	return kp, nil
+}
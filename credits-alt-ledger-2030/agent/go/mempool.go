@@ -0,0 +1,245 @@
+/*
+ * Transaction mempool
+ *
+ * Bounded, priority-ordered holding area for transactions that have not yet
+ * been committed into a block. Modeled loosely on neo-go's
+ * pkg/core/mempool: one slot per sender+nonce (a resubmission replaces
+ * rather than queues), eviction of the lowest-priority transaction once the
+ * pool is full, and a pluggable Policy checked before anything is admitted.
+ */
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Policy is an admission check layered on top of the mempool's structural
+// rules (capacity, replacement). CommitBlock and any inbound network path
+// must route transactions through CheckTx before they reach the mempool.
+type Policy interface {
+	CheckTx(tx AgentTransaction) error
+}
+
+// PolicyFunc adapts a plain function to a Policy.
+type PolicyFunc func(tx AgentTransaction) error
+
+// CheckTx implements Policy.
+func (f PolicyFunc) CheckTx(tx AgentTransaction) error {
+	return f(tx)
+}
+
+// AllowAllPolicy is the default Policy: every structurally valid
+// transaction is admitted.
+func AllowAllPolicy() Policy {
+	return PolicyFunc(func(AgentTransaction) error { return nil })
+}
+
+// MemPoolConfig controls a MemPool's capacity and replacement rules.
+type MemPoolConfig struct {
+	// Capacity is the maximum number of transactions the pool holds before
+	// it starts evicting the lowest-priority entry to make room.
+	Capacity int
+	// MinReplacementFeeBump is the minimum percentage by which a
+	// replacement transaction's GasPrice must exceed the one it replaces,
+	// e.g. 10 requires at least a 10% bump.
+	MinReplacementFeeBump uint64
+}
+
+// DefaultMemPoolConfig returns the configuration used by
+// NewPersonalAgentChain.
+func DefaultMemPoolConfig() MemPoolConfig {
+	return MemPoolConfig{
+		Capacity:              50000,
+		MinReplacementFeeBump: 10,
+	}
+}
+
+// MemPool holds not-yet-committed transactions. Transactions are indexed by
+// sender and nonce so a duplicate-nonce submission is resolved by fee
+// replacement instead of being queued alongside the original, and iterated
+// in priority order (highest GasPrice first) so a block producer can pack
+// directly from Pending.
+type MemPool struct {
+	mu       sync.RWMutex
+	cfg      MemPoolConfig
+	policy   Policy
+	byID     map[TransactionHash]AgentTransaction
+	bySender map[AgentAddress]map[uint64]TransactionHash
+	emit     func(ChainEvent)
+}
+
+// NewMemPool creates an empty mempool. emit may be nil, in which case
+// tx_added/tx_evicted/tx_replaced events are dropped.
+func NewMemPool(cfg MemPoolConfig, policy Policy, emit func(ChainEvent)) *MemPool {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultMemPoolConfig().Capacity
+	}
+	if policy == nil {
+		policy = AllowAllPolicy()
+	}
+	return &MemPool{
+		cfg:      cfg,
+		policy:   policy,
+		byID:     make(map[TransactionHash]AgentTransaction),
+		bySender: make(map[AgentAddress]map[uint64]TransactionHash),
+		emit:     emit,
+	}
+}
+
+// SetPolicy replaces the admission policy applied to future calls to Add.
+func (mp *MemPool) SetPolicy(policy Policy) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if policy == nil {
+		policy = AllowAllPolicy()
+	}
+	mp.policy = policy
+}
+
+// Add runs tx through the policy and, if accepted, admits it to the pool.
+// A transaction reusing a sender+nonce already pooled replaces it only if
+// its GasPrice clears MinReplacementFeeBump; otherwise it is rejected. If
+// the pool is at capacity, the lowest-priority transaction is evicted to
+// make room, provided tx outranks it.
+func (mp *MemPool) Add(tx AgentTransaction) error {
+	if err := mp.policy.CheckTx(tx); err != nil {
+		return fmt.Errorf("tx rejected by policy: %v", err)
+	}
+
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	senderTxs, ok := mp.bySender[tx.From]
+	if !ok {
+		senderTxs = make(map[uint64]TransactionHash)
+		mp.bySender[tx.From] = senderTxs
+	}
+
+	if existingID, exists := senderTxs[tx.Operation.Nonce]; exists {
+		if existingID == tx.ID {
+			return fmt.Errorf("transaction %x already in mempool", tx.ID)
+		}
+		existing := mp.byID[existingID]
+		if !sufficientFeeBump(existing.Operation.GasPrice, tx.Operation.GasPrice, mp.cfg.MinReplacementFeeBump) {
+			return fmt.Errorf("replacement for sender %s nonce %d needs at least %d%% higher gas price", tx.From, tx.Operation.Nonce, mp.cfg.MinReplacementFeeBump)
+		}
+		delete(mp.byID, existingID)
+		mp.emitLocked("tx_replaced", tx, existingID)
+	} else if len(mp.byID) >= mp.cfg.Capacity {
+		if !mp.evictLowestPriorityLocked(tx) {
+			return fmt.Errorf("mempool full at capacity %d", mp.cfg.Capacity)
+		}
+	}
+
+	mp.byID[tx.ID] = tx
+	senderTxs[tx.Operation.Nonce] = tx.ID
+	mp.emitLocked("tx_added", tx, TransactionHash{})
+	return nil
+}
+
+// sufficientFeeBump reports whether newPrice clears the required bump over
+// oldPrice. newPrice must strictly exceed oldPrice even when the percentage
+// check alone would pass at equal prices (e.g. both zero), otherwise a
+// same-(sender,nonce) resubmission with no fee bump at all would silently
+// replace the original.
+func sufficientFeeBump(oldPrice, newPrice, bumpPercent uint64) bool {
+	return newPrice > oldPrice && newPrice*100 >= oldPrice*(100+bumpPercent)
+}
+
+// evictLowestPriorityLocked removes the single lowest-GasPrice transaction
+// from the pool if candidate outranks it, reporting whether it did so.
+// Callers must hold mp.mu.
+func (mp *MemPool) evictLowestPriorityLocked(candidate AgentTransaction) bool {
+	var lowestID TransactionHash
+	var lowest AgentTransaction
+	found := false
+	for id, tx := range mp.byID {
+		if !found || tx.Operation.GasPrice < lowest.Operation.GasPrice {
+			lowestID, lowest, found = id, tx, true
+		}
+	}
+	if !found || lowest.Operation.GasPrice >= candidate.Operation.GasPrice {
+		return false
+	}
+
+	delete(mp.byID, lowestID)
+	if senderTxs, ok := mp.bySender[lowest.From]; ok {
+		delete(senderTxs, lowest.Operation.Nonce)
+	}
+	mp.emitLocked("tx_evicted", lowest, TransactionHash{})
+	return true
+}
+
+// Remove drops a transaction from the pool, e.g. once it has been
+// committed into a block.
+func (mp *MemPool) Remove(id TransactionHash) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	tx, ok := mp.byID[id]
+	if !ok {
+		return
+	}
+	delete(mp.byID, id)
+	if senderTxs, ok := mp.bySender[tx.From]; ok {
+		delete(senderTxs, tx.Operation.Nonce)
+	}
+}
+
+// Len returns the number of transactions currently pooled.
+func (mp *MemPool) Len() int {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+	return len(mp.byID)
+}
+
+// Pending returns pooled transactions in priority order (highest GasPrice
+// first, ties broken by earliest Timestamp), greedily packed so their
+// total canonical-encoding size does not exceed maxSize and their total
+// GasLimit does not exceed maxGas. A zero limit is treated as unbounded.
+func (mp *MemPool) Pending(maxSize, maxGas uint64) []AgentTransaction {
+	mp.mu.RLock()
+	txs := make([]AgentTransaction, 0, len(mp.byID))
+	for _, tx := range mp.byID {
+		txs = append(txs, tx)
+	}
+	mp.mu.RUnlock()
+
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].Operation.GasPrice != txs[j].Operation.GasPrice {
+			return txs[i].Operation.GasPrice > txs[j].Operation.GasPrice
+		}
+		return txs[i].Timestamp < txs[j].Timestamp
+	})
+
+	packed := make([]AgentTransaction, 0, len(txs))
+	var size, gas uint64
+	for _, tx := range txs {
+		txSize := uint64(len(encodeTransactionForSigning(tx)))
+		if maxSize > 0 && size+txSize > maxSize {
+			continue
+		}
+		if maxGas > 0 && gas+tx.Operation.GasLimit > maxGas {
+			continue
+		}
+		size += txSize
+		gas += tx.Operation.GasLimit
+		packed = append(packed, tx)
+	}
+	return packed
+}
+
+// emitLocked builds and emits a mempool event. Callers must hold mp.mu.
+// replaced is the id of the transaction tx displaced, if any.
+func (mp *MemPool) emitLocked(eventType string, tx AgentTransaction, replaced TransactionHash) {
+	if mp.emit == nil {
+		return
+	}
+	data := map[string]interface{}{"tx": tx}
+	if replaced != (TransactionHash{}) {
+		data["replaced"] = replaced
+	}
+	mp.emit(ChainEvent{Type: eventType, Data: data, Timestamp: tx.Timestamp})
+}
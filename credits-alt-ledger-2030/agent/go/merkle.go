@@ -0,0 +1,254 @@
+/*
+ * Deterministic Merkle state root and inclusion proofs
+ *
+ * calculateStateRoot used to hash state fields in Go map iteration order,
+ * which is not stable even across two runs of the same process, let alone
+ * across nodes. MPT replaces it with a binary Merkle tree over leaves
+ * sorted by key, analogous in spirit to Ethereum/Neo's state trie but
+ * without the radix-trie complexity this chain doesn't need yet: two nodes
+ * holding the same logical state always compute the same root, and
+ * GetStateProof/VerifyStateProof let a reader check a single key without
+ * pulling the whole chain. The same leaf/branch hashing also backs the
+ * block's transaction Merkle root and GetTxInclusionProof.
+ */
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Reserved MPT keys for the AgentChainState fields that aren't part of the
+// free-form StateData map. The leading NUL cannot appear in a StateData key
+// set through SetStateData (those come from Go string literals in caller
+// code), so these can't collide with one.
+const (
+	StateKeyOwner   = "\x00owner"
+	StateKeyNonce   = "\x00nonce"
+	StateKeyBalance = "\x00balance"
+	StateKeyHeight  = "\x00height"
+)
+
+// Proof is an inclusion proof for one leaf of a Merkle tree built by this
+// file: the sibling hash at each level from the leaf up to the root, and
+// which side of the pair that sibling sits on.
+type Proof struct {
+	// Siblings holds one hash per tree level, leaf-to-root.
+	Siblings [][32]byte
+	// Left[i] reports whether Siblings[i] sits to the left of the hash
+	// being proven at that level (i.e. the proven hash goes on the right
+	// when recombining).
+	Left []bool
+}
+
+func leafHash(data []byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{0x00}) // leaf domain tag
+	h.Write(data)
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+func branchHash(left, right [32]byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{0x01}) // branch domain tag, distinct from the leaf tag
+	h.Write(left[:])
+	h.Write(right[:])
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// buildMerkleLayers builds every level of a binary Merkle tree over leaves,
+// bottom (leaves[0]) to top (root, the single element of the last layer).
+// An odd layer is padded by duplicating its last element, the same
+// convention Bitcoin's transaction tree uses.
+func buildMerkleLayers(leaves [][32]byte) [][][32]byte {
+	if len(leaves) == 0 {
+		return [][][32]byte{{sha256.Sum256([]byte("empty-merkle-tree"))}}
+	}
+
+	layers := [][][32]byte{leaves}
+	layer := leaves
+	for len(layer) > 1 {
+		next := make([][32]byte, 0, (len(layer)+1)/2)
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 < len(layer) {
+				next = append(next, branchHash(layer[i], layer[i+1]))
+			} else {
+				next = append(next, branchHash(layer[i], layer[i]))
+			}
+		}
+		layers = append(layers, next)
+		layer = next
+	}
+	return layers
+}
+
+// proofForIndex walks layers from leaf index pos up to the root, collecting
+// the sibling at each level.
+func proofForIndex(layers [][][32]byte, pos int) *Proof {
+	proof := &Proof{}
+	for level := 0; level < len(layers)-1; level++ {
+		layer := layers[level]
+		if pos%2 == 0 {
+			siblingIdx := pos + 1
+			if siblingIdx >= len(layer) {
+				siblingIdx = pos // odd tail was duplicated against itself
+			}
+			proof.Siblings = append(proof.Siblings, layer[siblingIdx])
+			proof.Left = append(proof.Left, false)
+		} else {
+			proof.Siblings = append(proof.Siblings, layer[pos-1])
+			proof.Left = append(proof.Left, true)
+		}
+		pos /= 2
+	}
+	return proof
+}
+
+// verifyProof recombines leaf with proof's siblings and reports whether the
+// result matches root.
+func verifyProof(leaf [32]byte, proof *Proof, root [32]byte) bool {
+	if proof == nil || len(proof.Siblings) != len(proof.Left) {
+		return false
+	}
+	hash := leaf
+	for i, sibling := range proof.Siblings {
+		if proof.Left[i] {
+			hash = branchHash(sibling, hash)
+		} else {
+			hash = branchHash(hash, sibling)
+		}
+	}
+	return hash == root
+}
+
+// MPT is a sparse Merkle tree over the agent chain's state: the reserved
+// StateKey* fields plus every key set through SetStateData. Values are
+// encoded as an 8-byte big-endian length prefix followed by the raw bytes,
+// so a leaf hash is unambiguous regardless of what the value happens to
+// contain.
+type MPT struct {
+	mu     sync.RWMutex
+	leaves map[string][]byte
+}
+
+// NewMPT creates an empty state tree.
+func NewMPT() *MPT {
+	return &MPT{leaves: make(map[string][]byte)}
+}
+
+// Put sets (or overwrites) the value stored under key.
+func (t *MPT) Put(key string, value []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.leaves[key] = append([]byte(nil), value...)
+}
+
+func encodeLeafValue(key string, value []byte) []byte {
+	buf := make([]byte, 8, 8+len(key)+len(value))
+	binary.BigEndian.PutUint64(buf, uint64(len(key)))
+	buf = append(buf, key...)
+	buf = append(buf, value...)
+	return buf
+}
+
+// sortedKeysLocked returns the tree's keys in the canonical leaf order used
+// to compute the root and proofs. Callers must hold t.mu.
+func (t *MPT) sortedKeysLocked() []string {
+	keys := make([]string, 0, len(t.leaves))
+	for k := range t.leaves {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (t *MPT) layersLocked() (layers [][][32]byte, keys []string) {
+	keys = t.sortedKeysLocked()
+	leaves := make([][32]byte, len(keys))
+	for i, k := range keys {
+		leaves[i] = leafHash(encodeLeafValue(k, t.leaves[k]))
+	}
+	return buildMerkleLayers(leaves), keys
+}
+
+// Root returns the tree's current root hash.
+func (t *MPT) Root() [32]byte {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	layers, _ := t.layersLocked()
+	return layers[len(layers)-1][0]
+}
+
+// GetStateProof returns an inclusion proof for key's current value.
+func (t *MPT) GetStateProof(key string) (*Proof, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, ok := t.leaves[key]; !ok {
+		return nil, fmt.Errorf("no state entry for key %q", key)
+	}
+
+	layers, keys := t.layersLocked()
+	idx := sort.SearchStrings(keys, key)
+	return proofForIndex(layers, idx), nil
+}
+
+// VerifyStateProof reports whether proof demonstrates that key maps to
+// value under the state tree with the given root.
+func VerifyStateProof(root [32]byte, key string, value []byte, proof *Proof) bool {
+	return verifyProof(leafHash(encodeLeafValue(key, value)), proof, root)
+}
+
+// merkleRootOfTxs computes the Merkle root of a block's transactions, in
+// block order (unlike state leaves, transaction order is meaningful and
+// must not be sorted).
+func merkleRootOfTxs(txs []AgentTransaction) [32]byte {
+	layers := txLayers(txs)
+	return layers[len(layers)-1][0]
+}
+
+func txLayers(txs []AgentTransaction) [][][32]byte {
+	leaves := make([][32]byte, len(txs))
+	for i, tx := range txs {
+		leaves[i] = leafHash(tx.ID[:])
+	}
+	return buildMerkleLayers(leaves)
+}
+
+// GetTxInclusionProof returns a proof that txID was committed in the block
+// at blockHeight, along with that block's transaction Merkle root, against
+// which the proof can be checked with VerifyTxInclusionProof.
+func (pac *PersonalAgentChain) GetTxInclusionProof(blockHeight uint64, txID TransactionHash) (*Proof, [32]byte, error) {
+	block, err := pac.GetBlockByHeight(blockHeight)
+	if err != nil {
+		return nil, [32]byte{}, err
+	}
+
+	idx := -1
+	for i, tx := range block.Transactions {
+		if tx.ID == txID {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return nil, [32]byte{}, fmt.Errorf("transaction %x not found in block %d", txID, blockHeight)
+	}
+
+	layers := txLayers(block.Transactions)
+	root := layers[len(layers)-1][0]
+	return proofForIndex(layers, idx), root, nil
+}
+
+// VerifyTxInclusionProof reports whether proof demonstrates that txID is
+// included under the transaction Merkle root txRoot.
+func VerifyTxInclusionProof(txRoot [32]byte, txID TransactionHash, proof *Proof) bool {
+	return verifyProof(leafHash(txID[:]), proof, txRoot)
+}
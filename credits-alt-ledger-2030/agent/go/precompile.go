@@ -0,0 +1,210 @@
+/*
+ * Stateful precompiled contracts for agent chains
+ *
+ * Some operations (CNS registration, the transfer bridge, signature
+ * verification) are native Go logic rather than something a user-supplied
+ * AgentOperation interpreter should own, the same reasoning that puts
+ * things like ecrecover behind reserved addresses in Polaris/go-ethereum
+ * instead of bytecode. A PrecompileManager lets processTx dispatch a
+ * transaction addressed at one of those reserved addresses straight to a
+ * native handler instead of the AgentOperation.Type switch.
+ */
+package agent
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PrecompileContext carries everything a PrecompileContract.Run needs: the
+// chain's state as of the call, who's calling, how much value came with
+// the call, and a handle for reading/writing StateData. It is only valid
+// for the duration of the Run call that received it.
+type PrecompileContext struct {
+	State  AgentChainState
+	Caller AgentAddress
+	Value  uint64
+	DB     StateDB
+}
+
+// StateDB is the read/write handle a precompile gets into the calling
+// chain's free-form state, scoped to StateData so a precompile cannot
+// reach in and rewrite Balance/Nonce/Height directly.
+type StateDB interface {
+	GetStateData(key string) []byte
+	SetStateData(key string, value []byte)
+}
+
+// PrecompileContract is a native Go handler reachable by directing a
+// transaction's To address at it instead of the usual AgentOperation
+// interpreter.
+type PrecompileContract interface {
+	// Address is the reserved AgentAddress this contract is dispatched at.
+	Address() AgentAddress
+	// RequiredGas reports the gas method with args would consume, for
+	// callers that want to check affordability before calling Run.
+	RequiredGas(method string, args [][]byte) uint64
+	// Run executes method with args against ctx, returning the call's
+	// return value or an error if method is unknown or args are invalid.
+	Run(ctx *PrecompileContext, method string, args [][]byte) ([]byte, error)
+}
+
+// PrecompileManager answers whether an address is a registered precompile
+// and looks it up, the minimal surface processTx needs to dispatch a
+// transaction.
+type PrecompileManager interface {
+	Has(addr AgentAddress) bool
+	Get(addr AgentAddress) (PrecompileContract, bool)
+}
+
+// PrecompileRegistry is the default PrecompileManager: an address-keyed set
+// of PrecompileContracts that can be extended at runtime via Register.
+type PrecompileRegistry struct {
+	mu        sync.RWMutex
+	contracts map[AgentAddress]PrecompileContract
+}
+
+// NewPrecompileRegistry creates an empty registry.
+func NewPrecompileRegistry() *PrecompileRegistry {
+	return &PrecompileRegistry{contracts: make(map[AgentAddress]PrecompileContract)}
+}
+
+// Register adds contract under its own Address. It fails if that address
+// already has a contract registered, for the same reason
+// RegisterOperationHandler does: silently replacing one could change how
+// already-committed blocks would replay.
+func (r *PrecompileRegistry) Register(contract PrecompileContract) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	addr := contract.Address()
+	if _, exists := r.contracts[addr]; exists {
+		return fmt.Errorf("precompile already registered at address %x", addr)
+	}
+	r.contracts[addr] = contract
+	return nil
+}
+
+// Has reports whether addr is a registered precompile.
+func (r *PrecompileRegistry) Has(addr AgentAddress) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.contracts[addr]
+	return ok
+}
+
+// Get returns the contract registered at addr, if any.
+func (r *PrecompileRegistry) Get(addr AgentAddress) (PrecompileContract, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.contracts[addr]
+	return c, ok
+}
+
+// List returns every registered precompile's address, in no particular
+// order; it backs the CLI's precompile-list command.
+func (r *PrecompileRegistry) List() []AgentAddress {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	addrs := make([]AgentAddress, 0, len(r.contracts))
+	for addr := range r.contracts {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// chainStateDB adapts a PersonalAgentChain's already-locked state into a
+// StateDB for the duration of a precompile call. Callers must hold pac.mu.
+type chainStateDB struct {
+	pac *PersonalAgentChain
+}
+
+func (db chainStateDB) GetStateData(key string) []byte {
+	return db.pac.state.StateData[key]
+}
+
+func (db chainStateDB) SetStateData(key string, value []byte) {
+	db.pac.state.StateData[key] = value
+	db.pac.stateTree.Put(key, value)
+}
+
+// runPrecompile dispatches tx to the precompile registered at tx.To. The
+// transaction's Operation.Type is used as the precompile method name, and
+// Operation.Data["args"] (a JSON array of strings, the same convention
+// create-tx/precompile-call use to build it) supplies the method's
+// positional arguments. Callers must hold pac.mu.
+func (pac *PersonalAgentChain) runPrecompile(contract PrecompileContract, tx AgentTransaction) error {
+	args, err := precompileArgs(tx.Operation.Data)
+	if err != nil {
+		return fmt.Errorf("precompile call: %v", err)
+	}
+
+	ctx := &PrecompileContext{
+		State:  pac.state,
+		Caller: tx.From,
+		Value:  tx.Value,
+		DB:     chainStateDB{pac: pac},
+	}
+	_, err = contract.Run(ctx, tx.Operation.Type, args)
+	return err
+}
+
+// precompileArgs extracts the positional byte-string arguments of a
+// precompile-directed AgentOperation from its Data map's "args" entry.
+func precompileArgs(data map[string]interface{}) ([][]byte, error) {
+	raw, ok := data["args"]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`"args" must be a list of strings`)
+	}
+
+	args := make([][]byte, len(list))
+	for i, v := range list {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("arg %d must be a string", i)
+		}
+		args[i] = []byte(s)
+	}
+	return args, nil
+}
+
+// CallPrecompile runs a precompile synchronously against the chain's
+// current state without creating or committing a transaction, the
+// equivalent of a read-only "call" rather than a state-changing "send".
+// Any StateData writes the precompile makes during Run are applied, so
+// this is only safe for precompiles whose Run is idempotent when called
+// outside of block application (e.g. a pure read).
+func (pac *PersonalAgentChain) CallPrecompile(addr AgentAddress, caller AgentAddress, value uint64, method string, args [][]byte) ([]byte, error) {
+	pac.mu.Lock()
+	defer pac.mu.Unlock()
+
+	contract, ok := pac.precompiles.Get(addr)
+	if !ok {
+		return nil, fmt.Errorf("no precompile registered at address %x", addr)
+	}
+
+	ctx := &PrecompileContext{
+		State:  pac.state,
+		Caller: caller,
+		Value:  value,
+		DB:     chainStateDB{pac: pac},
+	}
+	return contract.Run(ctx, method, args)
+}
+
+// Precompiles returns the chain's PrecompileManager, for callers that want
+// to enumerate or query registered precompiles directly (e.g. the CLI's
+// precompile-list command).
+func (pac *PersonalAgentChain) Precompiles() PrecompileManager {
+	return pac.precompiles
+}
+
+// RegisterPrecompile adds contract to the chain's precompile registry. See
+// PrecompileRegistry.Register for the error case.
+func (pac *PersonalAgentChain) RegisterPrecompile(contract PrecompileContract) error {
+	return pac.precompiles.Register(contract)
+}
@@ -0,0 +1,167 @@
+/*
+ * agent_ namespace: the CLI's own verbs (status, balance, create-tx, ...)
+ * exposed as JSON-RPC methods against the same PersonalAgentChain, so
+ * external tooling and the eventual web UI don't have to scrape stdout.
+ */
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/credits/alt-ledger-2030/agent"
+)
+
+func init() {
+	methods["agent_status"] = handleGetState
+	methods["agent_balance"] = handleAgentBalance
+	methods["agent_createTransaction"] = handleCreateTransaction
+	methods["agent_commit"] = handleCommitBlock
+	methods["agent_transfer"] = handleAgentTransfer
+	methods["agent_setState"] = handleAgentSetState
+	methods["agent_getState"] = handleGetStateData
+	methods["agent_registerCNS"] = handleAgentRegisterCNS
+	methods["agent_resolveCNS"] = handleAgentResolveCNS
+	methods["agent_history"] = handleAgentHistory
+	methods["agent_export"] = handleAgentExport
+}
+
+func handleAgentBalance(chain *agent.PersonalAgentChain, _ json.RawMessage) (interface{}, error) {
+	return chain.GetState().Balance, nil
+}
+
+type agentTransferParams struct {
+	To     string `json:"to"`
+	Amount uint64 `json:"amount"`
+}
+
+func handleAgentTransfer(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p agentTransferParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	to, err := decodeHash32(p.To)
+	if err != nil {
+		return nil, fmt.Errorf("to: %v", err)
+	}
+	if err := chain.TransferValue(agent.AgentAddress(to), p.Amount); err != nil {
+		return nil, err
+	}
+	return chain.GetState(), nil
+}
+
+type agentSetStateParams struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func handleAgentSetState(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p agentSetStateParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	chain.SetStateData(p.Key, []byte(p.Value))
+	return nil, nil
+}
+
+type agentCNSRegisterParams struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Relay     string `json:"relay"`
+}
+
+func handleAgentRegisterCNS(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p agentCNSRegisterParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+
+	operation := agent.AgentOperation{
+		Type:     "register",
+		Data:     map[string]interface{}{"args": []interface{}{p.Namespace, p.Name, p.Relay}},
+		Nonce:    chain.GetState().Nonce + 1,
+		GasLimit: 50000,
+	}
+	return chain.CreateTransaction(agent.CNSPrecompileAddress, operation, 0)
+}
+
+type agentCNSResolveParams struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func handleAgentResolveCNS(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p agentCNSResolveParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+
+	result, err := chain.CallPrecompile(
+		agent.CNSPrecompileAddress,
+		chain.GetState().Owner,
+		0,
+		"resolve",
+		[][]byte{[]byte(p.Namespace), []byte(p.Name)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var record agent.CNSRecord
+	if err := json.Unmarshal(result, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode CNS record: %v", err)
+	}
+	return record, nil
+}
+
+// handleAgentHistory mirrors the CLI's own "history" command, which is
+// itself still a placeholder pending a persistent transaction history
+// store: there is nothing queryable here yet beyond current chain state.
+func handleAgentHistory(_ *agent.PersonalAgentChain, _ json.RawMessage) (interface{}, error) {
+	return map[string]string{
+		"message": "transaction history is not yet persisted; query agent_status or chain_getBlockByHeight for real-time state",
+	}, nil
+}
+
+type agentExportParams struct {
+	Format string `json:"format"`
+}
+
+type agentExportResult struct {
+	Format string `json:"format"`
+	Data   string `json:"data"`
+}
+
+func handleAgentExport(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p agentExportParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("invalid params: %v", err)
+		}
+	}
+	if p.Format == "" {
+		p.Format = "json"
+	}
+
+	state := chain.GetState()
+	switch p.Format {
+	case "json":
+		data, err := json.Marshal(state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal state: %v", err)
+		}
+		return agentExportResult{Format: "json", Data: string(data)}, nil
+
+	case "csv":
+		csv := fmt.Sprintf("Key,Value\nAddress,%s\nChain ID,%s\nHeight,%d\nNonce,%d\nBalance,%d\nDAG Height,%d\n",
+			state.Owner.String(), state.ChainID.String(), state.Height, state.Nonce, state.Balance, state.DAGHeight)
+		for key, value := range state.StateData {
+			csv += fmt.Sprintf("State_%s,%s\n", key, hex.EncodeToString(value))
+		}
+		return agentExportResult{Format: "csv", Data: csv}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s (supported: json, csv)", p.Format)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+ * JSON-RPC 2.0 surface for PersonalAgentChain
+ *
+ * Exposes a chain's read queries and mutating operations over HTTP so
+ * out-of-process tooling (dashboards, other services, scripts) can drive a
+ * chain the CLI already has open, instead of only in-process callers. The
+ * same Server also owns the WebSocket event feed in ws.go.
+ */
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/credits/alt-ledger-2030/agent"
+)
+
+// Request is a JSON-RPC 2.0 request object.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// Response is a JSON-RPC 2.0 response object. Result and Error are
+// mutually exclusive, per the spec.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Standard JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// methodFunc handles one JSON-RPC method's raw params and returns its
+// result, or an error to report back as codeInvalidParams.
+type methodFunc func(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error)
+
+var methods = map[string]methodFunc{
+	"chain_getState":          handleGetState,
+	"chain_getBlockByHeight":  handleGetBlockByHeight,
+	"chain_getBlockByHash":    handleGetBlockByHash,
+	"chain_getTransaction":    handleGetTransaction,
+	"chain_getStateData":      handleGetStateData,
+	"chain_getStateProof":     handleGetStateProof,
+	"chain_createTransaction": handleCreateTransaction,
+	"chain_commitBlock":       handleCommitBlock,
+}
+
+// Server implements http.Handler, dispatching JSON-RPC 2.0 requests against
+// a single PersonalAgentChain and upgrading WebSocket requests to the
+// chain's ChainEvent feed.
+type Server struct {
+	chain *agent.PersonalAgentChain
+	hub   *Hub
+}
+
+// NewServer wraps chain for JSON-RPC access and starts republishing its
+// ChainEvents to the WebSocket feed at /ws.
+func NewServer(chain *agent.PersonalAgentChain) *Server {
+	hub := newHub()
+	hub.subscribeTo(chain)
+	return &Server{chain: chain, hub: hub}
+}
+
+// ServeHTTP routes POST /rpc to the JSON-RPC dispatcher and GET /ws to the
+// WebSocket event feed.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/ws" {
+		s.hub.serveWS(w, r)
+		return
+	}
+	s.serveRPC(w, r)
+}
+
+func (s *Server) serveRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "JSON-RPC requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(w, Response{JSONRPC: "2.0", Error: &Error{Code: codeParseError, Message: err.Error()}})
+		return
+	}
+
+	handler, ok := methods[req.Method]
+	if !ok {
+		writeResponse(w, Response{JSONRPC: "2.0", ID: req.ID, Error: &Error{
+			Code:    codeMethodNotFound,
+			Message: fmt.Sprintf("method %q not found", req.Method),
+		}})
+		return
+	}
+
+	result, err := handler(s.chain, req.Params)
+	if err != nil {
+		writeResponse(w, Response{JSONRPC: "2.0", ID: req.ID, Error: &Error{Code: codeInvalidParams, Message: err.Error()}})
+		return
+	}
+	writeResponse(w, Response{JSONRPC: "2.0", ID: req.ID, Result: result})
+}
+
+func writeResponse(w http.ResponseWriter, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// decodeHash32 parses a 32-byte hex string into an array type such as
+// agent.TransactionHash or agent.AgentAddress, both of which are [32]byte
+// under the hood and so marshal as arrays of numbers, not hex, through
+// encoding/json. RPC params use hex instead, matching how the CLI already
+// accepts addresses and hashes from its own command line.
+func decodeHash32(s string) ([32]byte, error) {
+	var out [32]byte
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return out, fmt.Errorf("invalid hex: %v", err)
+	}
+	if len(b) != 32 {
+		return out, fmt.Errorf("expected 32 bytes, got %d", len(b))
+	}
+	copy(out[:], b)
+	return out, nil
+}
+
+func handleGetState(chain *agent.PersonalAgentChain, _ json.RawMessage) (interface{}, error) {
+	return chain.GetState(), nil
+}
+
+type heightParams struct {
+	Height uint64 `json:"height"`
+}
+
+func handleGetBlockByHeight(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p heightParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	return chain.GetBlockByHeight(p.Height)
+}
+
+type hashParams struct {
+	Hash string `json:"hash"`
+}
+
+func handleGetBlockByHash(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p hashParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	hash, err := decodeHash32(p.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("hash: %v", err)
+	}
+	return chain.GetBlockByHash(agent.TransactionHash(hash))
+}
+
+func handleGetTransaction(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p hashParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	hash, err := decodeHash32(p.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("hash: %v", err)
+	}
+	return chain.GetTransaction(agent.TransactionHash(hash))
+}
+
+type stateDataParams struct {
+	Key string `json:"key"`
+}
+
+func handleGetStateData(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p stateDataParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	return hex.EncodeToString(chain.GetStateData(p.Key)), nil
+}
+
+type stateProofResult struct {
+	Proof *agent.Proof `json:"proof"`
+	Root  string       `json:"root"`
+}
+
+func handleGetStateProof(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p stateDataParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	proof, root, err := chain.GetStateProof(p.Key)
+	if err != nil {
+		return nil, err
+	}
+	return stateProofResult{Proof: proof, Root: hex.EncodeToString(root[:])}, nil
+}
+
+type createTransactionParams struct {
+	To        string               `json:"to"`
+	Operation agent.AgentOperation `json:"operation"`
+	Value     uint64               `json:"value"`
+}
+
+func handleCreateTransaction(chain *agent.PersonalAgentChain, params json.RawMessage) (interface{}, error) {
+	var p createTransactionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	to, err := decodeHash32(p.To)
+	if err != nil {
+		return nil, fmt.Errorf("to: %v", err)
+	}
+	return chain.CreateTransaction(agent.AgentAddress(to), p.Operation, p.Value)
+}
+
+func handleCommitBlock(chain *agent.PersonalAgentChain, _ json.RawMessage) (interface{}, error) {
+	return chain.CommitBlock()
+}
@@ -0,0 +1,257 @@
+package rpc
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/credits/alt-ledger-2030/agent"
+)
+
+// wsAcceptMagic is the fixed GUID RFC 6455 defines for deriving
+// Sec-WebSocket-Accept from the client's Sec-WebSocket-Key.
+const wsAcceptMagic = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// publishedEventTypes are the ChainEvent.Type values the WebSocket feed
+// republishes, mirroring the set the CLI's own setupEventHandlers logs.
+var publishedEventTypes = []string{
+	"transaction_created",
+	"block_committed",
+	"state_updated",
+	"dag_synced",
+}
+
+// Hub fans out a chain's ChainEvents to every connected WebSocket client,
+// each optionally filtered to a subset of event types.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[*wsConn]map[string]bool // nil filter means "all types"
+}
+
+func newHub() *Hub {
+	return &Hub{subscribers: make(map[*wsConn]map[string]bool)}
+}
+
+// subscribeTo registers the hub against every type in publishedEventTypes
+// so chain's events reach connected clients as they're emitted.
+func (h *Hub) subscribeTo(chain *agent.PersonalAgentChain) {
+	for _, eventType := range publishedEventTypes {
+		chain.AddEventHandler(eventType, h.broadcast)
+	}
+}
+
+func (h *Hub) broadcast(event agent.ChainEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn, filter := range h.subscribers {
+		if filter != nil && !filter[event.Type] {
+			continue
+		}
+		if err := conn.writeMessage(opText, payload); err != nil {
+			conn.Close()
+			delete(h.subscribers, conn)
+		}
+	}
+}
+
+// serveWS upgrades r to a WebSocket connection and registers it for
+// broadcast until the client disconnects. The "types" query parameter, a
+// comma-separated list of ChainEvent.Type values, restricts which events
+// that connection receives; omitting it subscribes to everything.
+func (h *Hub) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrade(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var filter map[string]bool
+	if types := r.URL.Query().Get("types"); types != "" {
+		filter = make(map[string]bool)
+		for _, t := range strings.Split(types, ",") {
+			filter[strings.TrimSpace(t)] = true
+		}
+	}
+
+	h.mu.Lock()
+	h.subscribers[conn] = filter
+	h.mu.Unlock()
+
+	// Drain (and discard) client frames until the connection closes, which
+	// is all a publish-only feed needs: this unblocks on a client close or
+	// read error and lets us drop the subscriber.
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			delete(h.subscribers, conn)
+			h.mu.Unlock()
+			conn.Close()
+		}()
+		for {
+			if _, _, err := conn.readMessage(); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// WebSocket opcodes used by this minimal server.
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+// wsConn is a hijacked HTTP connection speaking the RFC 6455 framing
+// needed for this package's purposes: unmasked server-to-client frames,
+// masked client-to-server frames, and single-frame (unfragmented)
+// messages, which is all a JSON event feed ever sends.
+type wsConn struct {
+	mu   sync.Mutex
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// upgrade performs the RFC 6455 handshake on r and hijacks its underlying
+// connection, returning a wsConn ready for writeMessage/readMessage.
+func upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return nil, fmt.Errorf("expected Upgrade: websocket")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, fmt.Errorf("missing Sec-WebSocket-Key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, fmt.Errorf("connection does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("hijack failed: %v", err)
+	}
+
+	accept := wsAccept(key)
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + accept + "\r\n\r\n"
+	if _, err := rw.WriteString(resp); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &wsConn{conn: conn, br: rw.Reader}, nil
+}
+
+func wsAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key))
+	h.Write([]byte(wsAcceptMagic))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
+
+// writeMessage sends payload as a single unmasked frame, per RFC 6455
+// ("a server MUST NOT mask any frames that it sends to the client").
+func (c *wsConn) writeMessage(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := []byte{0x80 | opcode} // FIN set, no fragmentation
+	switch {
+	case len(payload) <= 125:
+		header = append(header, byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		ext := make([]byte, 2)
+		binary.BigEndian.PutUint16(ext, uint16(len(payload)))
+		header = append(header, 126)
+		header = append(header, ext...)
+	default:
+		ext := make([]byte, 8)
+		binary.BigEndian.PutUint64(ext, uint64(len(payload)))
+		header = append(header, 127)
+		header = append(header, ext...)
+	}
+
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// readMessage reads one client frame, unmasking it per spec ("the client
+// MUST mask all frames ... sent to the server"). It replies to pings with
+// a pong and treats a close frame as io.EOF.
+func (c *wsConn) readMessage() (opcode byte, payload []byte, err error) {
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(c.br, head); err != nil {
+		return 0, nil, err
+	}
+	opcode = head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.br, ext); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.br, ext); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	var maskKey [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, maskKey[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= maskKey[i%4]
+		}
+	}
+
+	switch opcode {
+	case opClose:
+		return opcode, payload, io.EOF
+	case opPing:
+		c.writeMessage(opPong, payload)
+	}
+	return opcode, payload, nil
+}
@@ -0,0 +1,87 @@
+/*
+ * Simulated multi-agent backend for tests
+ *
+ * Applications built on package agent typically need more than one
+ * PersonalAgentChain talking to each other (a transfer, an HTLC swap, a 2PC
+ * transaction) to exercise their own code end-to-end. Wiring that up for
+ * real means generating keystores, standing up transport.SecureNetworkClient
+ * listeners, and persisting to disk. Backend does the in-memory equivalent:
+ * every agent it creates shares one CrossAgentCoordinator with a nil
+ * NetworkClient, so cross-agent operations between them resolve through
+ * CrossAgentCoordinator's local path and never touch a socket.
+ */
+package simulated
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/credits/alt-ledger-2030/agent"
+)
+
+// Backend hosts a set of named, in-memory-backed agent chains that all
+// resolve cross-agent operations against each other locally.
+type Backend struct {
+	mu          sync.Mutex
+	coordinator *agent.CrossAgentCoordinator
+	chains      map[string]*agent.PersonalAgentChain
+}
+
+// NewBackend creates an empty Backend. Use AddAgent to populate it.
+func NewBackend() *Backend {
+	return &Backend{
+		coordinator: agent.NewCrossAgentCoordinator(nil, nil),
+		chains:      make(map[string]*agent.PersonalAgentChain),
+	}
+}
+
+// AddAgent creates a fresh in-memory PersonalAgentChain named name, with a
+// newly generated keypair, and registers it with the backend's shared
+// coordinator. name must not already be in use.
+func (b *Backend) AddAgent(name string) (*agent.PersonalAgentChain, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.chains[name]; exists {
+		return nil, fmt.Errorf("agent %q already exists", name)
+	}
+
+	keyPair, err := agent.GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate keypair for %q: %v", name, err)
+	}
+	chain, err := agent.NewPersonalAgentChain(keyPair, nil, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent chain for %q: %v", name, err)
+	}
+
+	b.coordinator.RegisterAgentChain(chain)
+	b.chains[name] = chain
+	return chain, nil
+}
+
+// Agent returns the chain previously created for name by AddAgent.
+func (b *Backend) Agent(name string) (*agent.PersonalAgentChain, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	chain, ok := b.chains[name]
+	return chain, ok
+}
+
+// Coordinator returns the CrossAgentCoordinator every agent added through
+// AddAgent is registered with, for driving AtomicTransfer/AtomicSwap/
+// AtomicSwapHTLC directly in tests.
+func (b *Backend) Coordinator() *agent.CrossAgentCoordinator {
+	return b.coordinator
+}
+
+// Commit commits name's pending transactions into a new block, the
+// in-memory equivalent of a miner/relayer calling CommitBlock on a live
+// node.
+func (b *Backend) Commit(name string) (*agent.AgentBlock, error) {
+	chain, ok := b.Agent(name)
+	if !ok {
+		return nil, fmt.Errorf("no agent named %q", name)
+	}
+	return chain.CommitBlock()
+}
@@ -0,0 +1,270 @@
+package agent
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+)
+
+// Key prefixes used by the bundled Store implementations in package store,
+// exposed here so tooling that walks a raw store (e.g. an `agent-cli
+// history` command) can make sense of it regardless of which backend wrote
+// it.
+const (
+	StoreBlockPrefix  = "b:" // block hash -> encoded AgentBlock
+	StoreHeightPrefix = "h:" // height -> block hash
+	StoreTxPrefix     = "t:" // tx hash -> encoded (height, index)
+	StoreStatePrefix  = "s:" // fixed key -> encoded AgentChainState snapshot
+)
+
+// Store persists everything a PersonalAgentChain needs to survive a
+// process restart: committed blocks, the latest chain state snapshot, and
+// a transaction index for resolving a TransactionHash back to the block
+// that committed it. A nil Store passed to NewPersonalAgentChain means
+// "keep everything in memory" (see store.NewMemStore).
+type Store interface {
+	// PutBlock persists a block, retrievable later by hash or height.
+	PutBlock(block AgentBlock) error
+	// GetBlock looks up a block by hash.
+	GetBlock(hash TransactionHash) (*AgentBlock, bool, error)
+	// GetBlockByHeight looks up a block by height.
+	GetBlockByHeight(height uint64) (*AgentBlock, bool, error)
+
+	// PutState persists the chain's current state snapshot, overwriting
+	// whatever snapshot was stored before.
+	PutState(state AgentChainState) error
+	// GetState loads the most recently persisted state snapshot, if any.
+	GetState() (*AgentChainState, bool, error)
+
+	// PutTxLookup records the (height, index) a transaction was committed
+	// at, so it can be found without scanning every block.
+	PutTxLookup(txID TransactionHash, height uint64, index int) error
+
+	// Iterate calls fn for every stored key with the given prefix, in key
+	// order, stopping early once fn returns false. A prefix that is
+	// itself a full key doubles as a point lookup.
+	Iterate(prefix string, fn func(key string, value []byte) bool) error
+
+	// Batch runs fn against a Batch that buffers writes so they become
+	// visible atomically: either every write issued inside fn commits, or
+	// none of them do (e.g. because fn or the commit itself returned an
+	// error).
+	Batch(fn func(b Batch) error) error
+
+	// Close releases any resources (file handles, DB connections) held by
+	// the store.
+	Close() error
+}
+
+// HistoryFilter narrows a HistoryIndex query. A nil From/To and an empty Op
+// leave that dimension unconstrained; a zero Since leaves the time bound
+// unconstrained; a zero Limit means unlimited.
+type HistoryFilter struct {
+	From  *AgentAddress
+	To    *AgentAddress
+	Op    string
+	Since uint64
+	Limit int
+}
+
+// HistoryIndex is a Store capability for querying committed transactions by
+// from/to address or operation type instead of scanning every block. It is
+// optional: the in-memory stores don't bother implementing it, since there's
+// nothing to persist across a restart to query in the first place, but a
+// persistent Store like store.BoltStore should. PersonalAgentChain.History
+// returns an error when the configured Store doesn't implement it.
+type HistoryIndex interface {
+	// PutHistoryEntries indexes every transaction in block by from, to, and
+	// operation type. Called once per committed block, after the block
+	// itself is durably written.
+	PutHistoryEntries(block AgentBlock) error
+
+	// QueryHistoryByFrom, QueryHistoryByTo and QueryHistoryByOp return
+	// matching transaction hashes, most recently committed first, up to
+	// limit entries (0 means unlimited).
+	QueryHistoryByFrom(from AgentAddress, limit int) ([]TransactionHash, error)
+	QueryHistoryByTo(to AgentAddress, limit int) ([]TransactionHash, error)
+	QueryHistoryByOp(op string, limit int) ([]TransactionHash, error)
+
+	// QueryHistoryAll returns every indexed transaction hash, most recently
+	// committed first, up to limit entries (0 means unlimited). Used when a
+	// HistoryFilter constrains only Since and/or Limit.
+	QueryHistoryAll(limit int) ([]TransactionHash, error)
+}
+
+// Batch buffers writes issued inside Store.Batch. CommitBlock uses it to
+// write a block, the state snapshot that results from processing it, and
+// every transaction's lookup entry as a single atomic unit, so a crash
+// mid-commit cannot leave the store with a block but no matching state (or
+// vice versa).
+type Batch interface {
+	PutBlock(block AgentBlock) error
+	PutState(state AgentChainState) error
+	PutTxLookup(txID TransactionHash, height uint64, index int) error
+}
+
+// EncodeTxLookup and DecodeTxLookup give every Store implementation a
+// shared on-disk format for PutTxLookup values, so GetTransaction (which
+// reads the value back via Iterate) can decode a result from any backend.
+func EncodeTxLookup(height uint64, index int) []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], height)
+	binary.BigEndian.PutUint64(buf[8:], uint64(index))
+	return buf
+}
+
+func DecodeTxLookup(data []byte) (height uint64, index int, ok bool) {
+	if len(data) != 16 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint64(data[:8]), int(binary.BigEndian.Uint64(data[8:])), true
+}
+
+// memStore is the zero-configuration Store NewPersonalAgentChain falls
+// back to when given a nil Store: it keeps PersonalAgentChain's pre-Store
+// behavior (nothing survives process exit) while still letting
+// GetBlockByHash/GetBlockByHeight/GetTransaction work. Callers that want an
+// in-memory store they can hold onto directly (e.g. to assert its type, or
+// to share it between a chain and other tooling) should construct one from
+// package store instead.
+type memStore struct {
+	mu        sync.RWMutex
+	blocks    map[TransactionHash]AgentBlock
+	byHeight  map[uint64]TransactionHash
+	txLookups map[string][]byte
+	state     *AgentChainState
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		blocks:    make(map[TransactionHash]AgentBlock),
+		byHeight:  make(map[uint64]TransactionHash),
+		txLookups: make(map[string][]byte),
+	}
+}
+
+func (s *memStore) PutBlock(block AgentBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocks[block.Hash] = block
+	s.byHeight[block.Height] = block.Hash
+	return nil
+}
+
+func (s *memStore) GetBlock(hash TransactionHash) (*AgentBlock, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	block, ok := s.blocks[hash]
+	if !ok {
+		return nil, false, nil
+	}
+	return &block, true, nil
+}
+
+func (s *memStore) GetBlockByHeight(height uint64) (*AgentBlock, bool, error) {
+	s.mu.RLock()
+	hash, ok := s.byHeight[height]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	return s.GetBlock(hash)
+}
+
+func (s *memStore) PutState(state AgentChainState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = &state
+	return nil
+}
+
+func (s *memStore) GetState() (*AgentChainState, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.state == nil {
+		return nil, false, nil
+	}
+	state := *s.state
+	return &state, true, nil
+}
+
+func (s *memStore) PutTxLookup(txID TransactionHash, height uint64, index int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.txLookups[StoreTxPrefix+txID.String()] = EncodeTxLookup(height, index)
+	return nil
+}
+
+func (s *memStore) Iterate(prefix string, fn func(key string, value []byte) bool) error {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.txLookups))
+	for k := range s.txLookups {
+		if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	values := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		values[k] = s.txLookups[k]
+	}
+	s.mu.RUnlock()
+
+	for _, k := range keys {
+		if !fn(k, values[k]) {
+			break
+		}
+	}
+	return nil
+}
+
+type memBatch struct {
+	blocks        []AgentBlock
+	state         *AgentChainState
+	txLookups     map[TransactionHash][2]uint64
+	txLookupOrder []TransactionHash
+}
+
+func (b *memBatch) PutBlock(block AgentBlock) error {
+	b.blocks = append(b.blocks, block)
+	return nil
+}
+
+func (b *memBatch) PutState(state AgentChainState) error {
+	b.state = &state
+	return nil
+}
+
+func (b *memBatch) PutTxLookup(txID TransactionHash, height uint64, index int) error {
+	if _, exists := b.txLookups[txID]; !exists {
+		b.txLookupOrder = append(b.txLookupOrder, txID)
+	}
+	b.txLookups[txID] = [2]uint64{height, uint64(index)}
+	return nil
+}
+
+func (s *memStore) Batch(fn func(b Batch) error) error {
+	batch := &memBatch{txLookups: make(map[TransactionHash][2]uint64)}
+	if err := fn(batch); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, block := range batch.blocks {
+		s.blocks[block.Hash] = block
+		s.byHeight[block.Height] = block.Hash
+	}
+	if batch.state != nil {
+		s.state = batch.state
+	}
+	for _, txID := range batch.txLookupOrder {
+		pair := batch.txLookups[txID]
+		s.txLookups[StoreTxPrefix+txID.String()] = EncodeTxLookup(pair[0], int(pair[1]))
+	}
+	return nil
+}
+
+func (s *memStore) Close() error {
+	return nil
+}
@@ -0,0 +1,467 @@
+/*
+ * Persistent storage backends for PersonalAgentChain
+ *
+ * MemStore is the default, used whenever NewPersonalAgentChain is given a
+ * nil Store. BoltStore is the on-disk backend, a single bbolt file with one
+ * bucket per key prefix from agent.Store*Prefix; a real deployment points
+ * an agent's CLIConfig.DataDir at it to survive process restarts.
+ */
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/credits/alt-ledger-2030/agent"
+)
+
+const stateKey = "current"
+
+// History index buckets. Each maps "<field value>:<height>:<index>" to the
+// transaction hash, so a query for a given value can scan its bucket and
+// get results back in committed order without touching the tx lookup or
+// block buckets at all.
+var (
+	historyByFromBucket = []byte("hx-from")
+	historyByToBucket   = []byte("hx-to")
+	historyByOpBucket   = []byte("hx-op")
+	historyAllBucket    = []byte("hx-all")
+)
+
+var historyBuckets = [][]byte{historyByFromBucket, historyByToBucket, historyByOpBucket, historyAllBucket}
+
+// MemStore is an in-memory agent.Store. Nothing it holds survives process
+// exit; it exists for tests and for running a chain with no persistence
+// guarantees.
+type MemStore struct {
+	mu        sync.RWMutex
+	blocks    map[agent.TransactionHash]agent.AgentBlock
+	byHeight  map[uint64]agent.TransactionHash
+	txLookups map[string][]byte
+	state     *agent.AgentChainState
+}
+
+// NewMemStore creates an empty in-memory store.
+func NewMemStore() *MemStore {
+	return &MemStore{
+		blocks:    make(map[agent.TransactionHash]agent.AgentBlock),
+		byHeight:  make(map[uint64]agent.TransactionHash),
+		txLookups: make(map[string][]byte),
+	}
+}
+
+func (s *MemStore) PutBlock(block agent.AgentBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocks[block.Hash] = block
+	s.byHeight[block.Height] = block.Hash
+	return nil
+}
+
+func (s *MemStore) GetBlock(hash agent.TransactionHash) (*agent.AgentBlock, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	block, ok := s.blocks[hash]
+	if !ok {
+		return nil, false, nil
+	}
+	return &block, true, nil
+}
+
+func (s *MemStore) GetBlockByHeight(height uint64) (*agent.AgentBlock, bool, error) {
+	s.mu.RLock()
+	hash, ok := s.byHeight[height]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	return s.GetBlock(hash)
+}
+
+func (s *MemStore) PutState(state agent.AgentChainState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = &state
+	return nil
+}
+
+func (s *MemStore) GetState() (*agent.AgentChainState, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.state == nil {
+		return nil, false, nil
+	}
+	state := *s.state
+	return &state, true, nil
+}
+
+func (s *MemStore) PutTxLookup(txID agent.TransactionHash, height uint64, index int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.txLookups[agent.StoreTxPrefix+txKey(txID)] = agent.EncodeTxLookup(height, index)
+	return nil
+}
+
+func (s *MemStore) Iterate(prefix string, fn func(key string, value []byte) bool) error {
+	s.mu.RLock()
+	keys := make([]string, 0)
+	values := make(map[string][]byte, len(s.txLookups))
+	for k, v := range s.txLookups {
+		if hasPrefix(k, prefix) {
+			keys = append(keys, k)
+			values[k] = v
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !fn(k, values[k]) {
+			break
+		}
+	}
+	return nil
+}
+
+func hasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}
+
+func txKey(txID agent.TransactionHash) string {
+	return fmt.Sprintf("%x", txID[:])
+}
+
+// memBatch buffers writes for MemStore.Batch so a caller returning an error
+// partway through leaves the store untouched.
+type memBatch struct {
+	blocks     []agent.AgentBlock
+	state      *agent.AgentChainState
+	txLookups  map[agent.TransactionHash][2]uint64 // txID -> [height, index]
+	txLookupOrder []agent.TransactionHash
+}
+
+func (b *memBatch) PutBlock(block agent.AgentBlock) error {
+	b.blocks = append(b.blocks, block)
+	return nil
+}
+
+func (b *memBatch) PutState(state agent.AgentChainState) error {
+	b.state = &state
+	return nil
+}
+
+func (b *memBatch) PutTxLookup(txID agent.TransactionHash, height uint64, index int) error {
+	if _, exists := b.txLookups[txID]; !exists {
+		b.txLookupOrder = append(b.txLookupOrder, txID)
+	}
+	b.txLookups[txID] = [2]uint64{height, uint64(index)}
+	return nil
+}
+
+func (s *MemStore) Batch(fn func(b agent.Batch) error) error {
+	batch := &memBatch{txLookups: make(map[agent.TransactionHash][2]uint64)}
+	if err := fn(batch); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, block := range batch.blocks {
+		s.blocks[block.Hash] = block
+		s.byHeight[block.Height] = block.Hash
+	}
+	if batch.state != nil {
+		s.state = batch.state
+	}
+	for _, txID := range batch.txLookupOrder {
+		pair := batch.txLookups[txID]
+		s.txLookups[agent.StoreTxPrefix+txKey(txID)] = agent.EncodeTxLookup(pair[0], int(pair[1]))
+	}
+	return nil
+}
+
+func (s *MemStore) Close() error {
+	return nil
+}
+
+// BoltStore is a bbolt-backed agent.Store: one bucket per key prefix
+// (blocks, heights, tx lookups, state), all written inside a single bbolt
+// transaction per Batch call so the update is atomic even across buckets.
+type BoltStore struct {
+	db *bbolt.DB
+}
+
+var buckets = [][]byte{
+	[]byte(agent.StoreBlockPrefix),
+	[]byte(agent.StoreHeightPrefix),
+	[]byte(agent.StoreTxPrefix),
+	[]byte(agent.StoreStatePrefix),
+}
+
+// NewBoltStore opens (or creates) a bbolt database at path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store %s: %v", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		for _, bucket := range historyBuckets {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize buckets in %s: %v", path, err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func heightKey(height uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, height)
+	return b
+}
+
+func (s *BoltStore) PutBlock(block agent.AgentBlock) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return putBlock(tx, block)
+	})
+}
+
+func putBlock(tx *bbolt.Tx, block agent.AgentBlock) error {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return fmt.Errorf("failed to marshal block %x: %v", block.Hash, err)
+	}
+	if err := tx.Bucket([]byte(agent.StoreBlockPrefix)).Put(block.Hash[:], data); err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(agent.StoreHeightPrefix)).Put(heightKey(block.Height), block.Hash[:])
+}
+
+func (s *BoltStore) GetBlock(hash agent.TransactionHash) (*agent.AgentBlock, bool, error) {
+	var block *agent.AgentBlock
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket([]byte(agent.StoreBlockPrefix)).Get(hash[:])
+		if data == nil {
+			return nil
+		}
+		var b agent.AgentBlock
+		if err := json.Unmarshal(data, &b); err != nil {
+			return fmt.Errorf("failed to unmarshal block %x: %v", hash, err)
+		}
+		block = &b
+		return nil
+	})
+	return block, block != nil, err
+}
+
+func (s *BoltStore) GetBlockByHeight(height uint64) (*agent.AgentBlock, bool, error) {
+	var hash agent.TransactionHash
+	found := false
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket([]byte(agent.StoreHeightPrefix)).Get(heightKey(height))
+		if data == nil {
+			return nil
+		}
+		copy(hash[:], data)
+		found = true
+		return nil
+	})
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return s.GetBlock(hash)
+}
+
+func (s *BoltStore) PutState(state agent.AgentChainState) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return putState(tx, state)
+	})
+}
+
+func putState(tx *bbolt.Tx, state agent.AgentChainState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chain state: %v", err)
+	}
+	return tx.Bucket([]byte(agent.StoreStatePrefix)).Put([]byte(stateKey), data)
+}
+
+func (s *BoltStore) GetState() (*agent.AgentChainState, bool, error) {
+	var state *agent.AgentChainState
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket([]byte(agent.StoreStatePrefix)).Get([]byte(stateKey))
+		if data == nil {
+			return nil
+		}
+		var st agent.AgentChainState
+		if err := json.Unmarshal(data, &st); err != nil {
+			return fmt.Errorf("failed to unmarshal chain state: %v", err)
+		}
+		state = &st
+		return nil
+	})
+	return state, state != nil, err
+}
+
+func (s *BoltStore) PutTxLookup(txID agent.TransactionHash, height uint64, index int) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return putTxLookup(tx, txID, height, index)
+	})
+}
+
+func putTxLookup(tx *bbolt.Tx, txID agent.TransactionHash, height uint64, index int) error {
+	return tx.Bucket([]byte(agent.StoreTxPrefix)).Put(txID[:], agent.EncodeTxLookup(height, index))
+}
+
+func (s *BoltStore) Iterate(prefix string, fn func(key string, value []byte) bool) error {
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(agent.StoreTxPrefix)).ForEach(func(k, v []byte) error {
+			key := agent.StoreTxPrefix + fmt.Sprintf("%x", k)
+			if !hasPrefix(key, prefix) {
+				return nil
+			}
+			if !fn(key, v) {
+				return errStopIteration
+			}
+			return nil
+		})
+	})
+	if err == errStopIteration {
+		return nil
+	}
+	return err
+}
+
+// errStopIteration unwinds bbolt's ForEach early; Iterate catches it so it
+// never surfaces to the caller as a real error.
+var errStopIteration = fmt.Errorf("stop iteration")
+
+func (s *BoltStore) Batch(fn func(b agent.Batch) error) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		batch := &boltBatch{tx: tx}
+		return fn(batch)
+	})
+}
+
+// boltBatch writes straight into the bbolt transaction it was handed, so
+// Batch's atomicity comes directly from bbolt's own transaction guarantees.
+type boltBatch struct {
+	tx *bbolt.Tx
+}
+
+func (b *boltBatch) PutBlock(block agent.AgentBlock) error {
+	return putBlock(b.tx, block)
+}
+
+func (b *boltBatch) PutState(state agent.AgentChainState) error {
+	return putState(b.tx, state)
+}
+
+func (b *boltBatch) PutTxLookup(txID agent.TransactionHash, height uint64, index int) error {
+	return putTxLookup(b.tx, txID, height, index)
+}
+
+// historyIndexKey orders entries for a given field value by commit order:
+// value, then a NUL separator (neither a hex address nor a typical
+// AgentOperation.Type contains one), then big-endian height and index so
+// lexicographic bucket order matches commit order.
+func historyIndexKey(value string, height uint64, index int) []byte {
+	key := make([]byte, 0, len(value)+1+8+4)
+	key = append(key, []byte(value)...)
+	key = append(key, 0)
+	key = append(key, heightKey(height)...)
+	idx := make([]byte, 4)
+	binary.BigEndian.PutUint32(idx, uint32(index))
+	return append(key, idx...)
+}
+
+// PutHistoryEntries implements agent.HistoryIndex.
+func (s *BoltStore) PutHistoryEntries(block agent.AgentBlock) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		for i, txn := range block.Transactions {
+			if err := tx.Bucket(historyByFromBucket).Put(historyIndexKey(txn.From.String(), block.Height, i), txn.ID[:]); err != nil {
+				return err
+			}
+			if err := tx.Bucket(historyByToBucket).Put(historyIndexKey(txn.To.String(), block.Height, i), txn.ID[:]); err != nil {
+				return err
+			}
+			if err := tx.Bucket(historyByOpBucket).Put(historyIndexKey(txn.Operation.Type, block.Height, i), txn.ID[:]); err != nil {
+				return err
+			}
+			if err := tx.Bucket(historyAllBucket).Put(historyIndexKey("", block.Height, i), txn.ID[:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// queryHistoryBucket returns every transaction hash stored under value in
+// bucketName, most recently committed first, up to limit entries (0 means
+// unlimited).
+func (s *BoltStore) queryHistoryBucket(bucketName []byte, value string, limit int) ([]agent.TransactionHash, error) {
+	prefix := append([]byte(value), 0)
+	var results []agent.TransactionHash
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(bucketName).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var txID agent.TransactionHash
+			copy(txID[:], v)
+			results = append(results, txID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// QueryHistoryByFrom implements agent.HistoryIndex.
+func (s *BoltStore) QueryHistoryByFrom(from agent.AgentAddress, limit int) ([]agent.TransactionHash, error) {
+	return s.queryHistoryBucket(historyByFromBucket, from.String(), limit)
+}
+
+// QueryHistoryByTo implements agent.HistoryIndex.
+func (s *BoltStore) QueryHistoryByTo(to agent.AgentAddress, limit int) ([]agent.TransactionHash, error) {
+	return s.queryHistoryBucket(historyByToBucket, to.String(), limit)
+}
+
+// QueryHistoryByOp implements agent.HistoryIndex.
+func (s *BoltStore) QueryHistoryByOp(op string, limit int) ([]agent.TransactionHash, error) {
+	return s.queryHistoryBucket(historyByOpBucket, op, limit)
+}
+
+// QueryHistoryAll implements agent.HistoryIndex.
+func (s *BoltStore) QueryHistoryAll(limit int) ([]agent.TransactionHash, error) {
+	return s.queryHistoryBucket(historyAllBucket, "", limit)
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
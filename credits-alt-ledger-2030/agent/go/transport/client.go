@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/credits/alt-ledger-2030/agent"
+)
+
+// Dialer opens a fresh transport-level connection to a peer. The caller is
+// expected to resolve AgentAddress to a network location however its
+// deployment does that (DNS, a directory service, a DHT, ...).
+type Dialer func(ctx context.Context, peer agent.AgentAddress) (io.ReadWriteCloser, error)
+
+// frameKind tags the encrypted payload so the receiver knows which
+// NetworkClient RPC it corresponds to.
+type frameKind string
+
+const (
+	framePrepare     frameKind = "prepare"
+	frameCommit      frameKind = "commit"
+	frameAbort       frameKind = "abort"
+	frameTermination frameKind = "termination"
+)
+
+type envelope struct {
+	Kind      frameKind                      `json:"kind"`
+	TxID      agent.CrossAgentTransactionID  `json:"tx_id"`
+	Operation *agent.AgentOperation          `json:"operation,omitempty"`
+	Success   bool                           `json:"success,omitempty"`
+	Status    agent.TransactionStatus        `json:"status,omitempty"`
+}
+
+// SecureNetworkClient implements agent.NetworkClient over authenticated,
+// encrypted Sessions established via Handshake. Every prepare/commit/abort
+// message is framed as a length-prefixed encrypted envelope with the
+// CrossAgentTransactionID bound into the AEAD associated data, so a message
+// from one transaction cannot be replayed into another.
+type SecureNetworkClient struct {
+	mu       sync.Mutex
+	sessions map[agent.AgentAddress]*Session
+	dial     Dialer
+	local    Identity
+	registry IdentityRegistry
+}
+
+// NewSecureNetworkClient creates a client that lazily establishes and caches
+// one authenticated session per peer.
+func NewSecureNetworkClient(local Identity, registry IdentityRegistry, dial Dialer) *SecureNetworkClient {
+	return &SecureNetworkClient{
+		sessions: make(map[agent.AgentAddress]*Session),
+		dial:     dial,
+		local:    local,
+		registry: registry,
+	}
+}
+
+func (c *SecureNetworkClient) sessionFor(ctx context.Context, peer agent.AgentAddress) (*Session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s, ok := c.sessions[peer]; ok {
+		return s, nil
+	}
+
+	conn, err := c.dial(ctx, peer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %v", peer, err)
+	}
+
+	session, err := Handshake(conn, c.local, c.registry, true, peer)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("handshake with %s failed: %v", peer, err)
+	}
+
+	c.sessions[peer] = session
+	return session, nil
+}
+
+// send seals env under the session's send AEAD, binding txID into the
+// associated data, and writes it as a length-prefixed frame.
+func (c *SecureNetworkClient) send(session *Session, txID agent.CrossAgentTransactionID, env envelope) error {
+	plain, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %v", err)
+	}
+
+	c.mu.Lock()
+	nonce := nonceFor(&session.sendNonce)
+	sealed := session.sendAEAD.Seal(nil, nonce, plain, txID[:])
+	c.mu.Unlock()
+
+	return writeFrame(session.conn, sealed)
+}
+
+func (c *SecureNetworkClient) recv(session *Session, txID agent.CrossAgentTransactionID) (envelope, error) {
+	frame, err := readFrame(session.conn)
+	if err != nil {
+		return envelope{}, fmt.Errorf("failed to read response frame: %v", err)
+	}
+
+	c.mu.Lock()
+	nonce := nonceFor(&session.recvNonce)
+	c.mu.Unlock()
+
+	plain, err := session.recvAEAD.Open(nil, nonce, frame, txID[:])
+	if err != nil {
+		return envelope{}, fmt.Errorf("failed to decrypt response: %v", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(plain, &env); err != nil {
+		return envelope{}, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+	return env, nil
+}
+
+// SendPrepareRequest implements agent.NetworkClient.
+func (c *SecureNetworkClient) SendPrepareRequest(ctx context.Context, participant agent.AgentAddress, operation agent.AgentOperation) (*agent.PrepareResponse, error) {
+	session, err := c.sessionFor(ctx, participant)
+	if err != nil {
+		return nil, err
+	}
+
+	// The prepare RPC is issued before a CrossAgentTransactionID exists for
+	// this hop from the participant's point of view, so we bind the zero
+	// value; the coordinator always re-sends commit/abort with the real ID.
+	var txID agent.CrossAgentTransactionID
+	if err := c.send(session, txID, envelope{Kind: framePrepare, TxID: txID, Operation: &operation}); err != nil {
+		return nil, err
+	}
+
+	env, err := c.recv(session, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agent.PrepareResponse{Participant: participant, Success: env.Success}, nil
+}
+
+// SendCommitRequest implements agent.NetworkClient.
+func (c *SecureNetworkClient) SendCommitRequest(ctx context.Context, participant agent.AgentAddress, txID agent.CrossAgentTransactionID) (*agent.CommitResponse, error) {
+	session, err := c.sessionFor(ctx, participant)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.send(session, txID, envelope{Kind: frameCommit, TxID: txID}); err != nil {
+		return nil, err
+	}
+	env, err := c.recv(session, txID)
+	if err != nil {
+		return nil, err
+	}
+	return &agent.CommitResponse{Participant: participant, Success: env.Success}, nil
+}
+
+// SendAbortRequest implements agent.NetworkClient.
+func (c *SecureNetworkClient) SendAbortRequest(ctx context.Context, participant agent.AgentAddress, txID agent.CrossAgentTransactionID) error {
+	session, err := c.sessionFor(ctx, participant)
+	if err != nil {
+		return err
+	}
+	return c.send(session, txID, envelope{Kind: frameAbort, TxID: txID})
+}
+
+// TerminationProtocol implements agent.NetworkClient.
+func (c *SecureNetworkClient) TerminationProtocol(ctx context.Context, peer agent.AgentAddress, txID agent.CrossAgentTransactionID) (agent.TransactionStatus, error) {
+	session, err := c.sessionFor(ctx, peer)
+	if err != nil {
+		return "", err
+	}
+	if err := c.send(session, txID, envelope{Kind: frameTermination, TxID: txID}); err != nil {
+		return "", err
+	}
+	env, err := c.recv(session, txID)
+	if err != nil {
+		return "", err
+	}
+	if env.Status == "" || env.Status == agent.StatusUnknown {
+		// Presumed-abort: a peer with no record of the transaction is
+		// treated the same as an explicit abort.
+		return agent.StatusAborted, nil
+	}
+	return env.Status, nil
+}
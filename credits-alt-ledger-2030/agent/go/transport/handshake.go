@@ -0,0 +1,259 @@
+/*
+ * Station-to-Station handshake and encrypted framing for agent-to-agent links
+ *
+ * Provides a mutually-authenticated, encrypted io.ReadWriteCloser on top of
+ * an arbitrary transport: each side contributes an ephemeral X25519 keypair,
+ * derives a shared secret over ECDH, and then proves possession of its
+ * long-term Ed25519 identity key by signing the exchanged ephemeral public
+ * keys under that shared secret. This binds the encrypted channel to a
+ * specific AgentAddress so a peer cannot inject prepare/commit/abort
+ * messages into someone else's 2PC transaction.
+ */
+package transport
+
+import (
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/credits/alt-ledger-2030/agent"
+)
+
+// nonceSize matches XChaCha20-Poly1305's extended nonce, which is large
+// enough that a per-direction counter can be incremented for the lifetime of
+// a session without any risk of reuse.
+const nonceSize = chacha20poly1305.NonceSizeX
+
+// Identity is an agent's long-term Ed25519 keypair, used to authenticate the
+// handshake. The corresponding AgentAddress is derived the same way
+// agent.NewPersonalAgentChain's caller derives it (sha256 of the public key).
+type Identity struct {
+	Address    agent.AgentAddress
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// IdentityRegistry resolves a peer's claimed AgentAddress to its registered
+// long-term Ed25519 public key, so a handshake can reject an impostor.
+type IdentityRegistry interface {
+	Lookup(addr agent.AgentAddress) (ed25519.PublicKey, bool)
+}
+
+// MapRegistry is a simple in-memory IdentityRegistry.
+type MapRegistry map[agent.AgentAddress]ed25519.PublicKey
+
+func (r MapRegistry) Lookup(addr agent.AgentAddress) (ed25519.PublicKey, bool) {
+	pub, ok := r[addr]
+	return pub, ok
+}
+
+// Session is an established, authenticated, encrypted channel to a single
+// peer. Frames are length-prefixed and each direction has its own AEAD
+// keyed off the handshake transcript, with a per-direction nonce counter so
+// sender and receiver never disagree on nonce state.
+type Session struct {
+	conn      io.ReadWriteCloser
+	peer      agent.AgentAddress
+	sendAEAD  cipher.AEAD
+	recvAEAD  cipher.AEAD
+	sendNonce uint64
+	recvNonce uint64
+}
+
+// Peer returns the authenticated address of the other end of the session.
+func (s *Session) Peer() agent.AgentAddress {
+	return s.peer
+}
+
+// Close closes the underlying connection.
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+// directionalKey derives an independent AEAD key per direction from the
+// shared secret and transcript, labeled by which side is sending, so the two
+// directions never reuse the same nonce space under the same key.
+func directionalKey(shared, transcript []byte, label byte) ([]byte, error) {
+	h := sha256.New()
+	h.Write(shared)
+	h.Write(transcript)
+	h.Write([]byte{label})
+	return h.Sum(nil), nil
+}
+
+// Handshake performs the STS-style exchange over conn and returns an
+// authenticated, encrypted Session. initiator must be true on exactly one
+// side of the connection. expectedPeer, if non-zero, is checked against the
+// address the remote side authenticates as.
+func Handshake(conn io.ReadWriteCloser, local Identity, registry IdentityRegistry, initiator bool, expectedPeer agent.AgentAddress) (*Session, error) {
+	curve := ecdh.X25519()
+	ephemeralPriv, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral keypair: %v", err)
+	}
+	localEphemeral := ephemeralPriv.PublicKey().Bytes()
+
+	var initiatorEphemeral, responderEphemeral []byte
+	if initiator {
+		if err := writeFrame(conn, localEphemeral); err != nil {
+			return nil, fmt.Errorf("failed to send ephemeral key: %v", err)
+		}
+		responderEphemeral, err = readFrame(conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read peer ephemeral key: %v", err)
+		}
+		initiatorEphemeral = localEphemeral
+	} else {
+		initiatorEphemeral, err = readFrame(conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read peer ephemeral key: %v", err)
+		}
+		if err := writeFrame(conn, localEphemeral); err != nil {
+			return nil, fmt.Errorf("failed to send ephemeral key: %v", err)
+		}
+		responderEphemeral = localEphemeral
+	}
+
+	peerEphemeralBytes := responderEphemeral
+	if !initiator {
+		peerEphemeralBytes = initiatorEphemeral
+	}
+	peerEphemeral, err := curve.NewPublicKey(peerEphemeralBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer ephemeral key: %v", err)
+	}
+
+	shared, err := ephemeralPriv.ECDH(peerEphemeral)
+	if err != nil {
+		return nil, fmt.Errorf("ECDH failed: %v", err)
+	}
+
+	// The transcript binds both ephemeral keys (in a fixed initiator-first
+	// order) into everything that follows, so a replayed or reordered
+	// handshake frame cannot be mistaken for a fresh session.
+	transcript := append(append([]byte{}, initiatorEphemeral...), responderEphemeral...)
+
+	initToResp, err := directionalKey(shared, transcript, 0x01)
+	if err != nil {
+		return nil, err
+	}
+	respToInit, err := directionalKey(shared, transcript, 0x02)
+	if err != nil {
+		return nil, err
+	}
+
+	initAEAD, err := chacha20poly1305.NewX(initToResp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build AEAD: %v", err)
+	}
+	respAEAD, err := chacha20poly1305.NewX(respToInit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build AEAD: %v", err)
+	}
+
+	var sendAEAD, recvAEAD cipher.AEAD
+	if initiator {
+		sendAEAD, recvAEAD = initAEAD, respAEAD
+	} else {
+		sendAEAD, recvAEAD = respAEAD, initAEAD
+	}
+
+	sig := ed25519.Sign(local.PrivateKey, transcript)
+	authMsg := append(append([]byte{}, local.Address[:]...), local.PublicKey...)
+	authMsg = append(authMsg, sig...)
+
+	var sendCounter, recvCounter uint64
+	sealed := sendAEAD.Seal(nil, nonceFor(&sendCounter), authMsg, transcript)
+
+	if initiator {
+		if err := writeFrame(conn, sealed); err != nil {
+			return nil, fmt.Errorf("failed to send auth frame: %v", err)
+		}
+	}
+
+	peerFrame, err := readFrame(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read peer auth frame: %v", err)
+	}
+	peerAuth, err := recvAEAD.Open(nil, nonceFor(&recvCounter), peerFrame, transcript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt peer auth frame: %v", err)
+	}
+
+	if !initiator {
+		if err := writeFrame(conn, sealed); err != nil {
+			return nil, fmt.Errorf("failed to send auth frame: %v", err)
+		}
+	}
+
+	if len(peerAuth) < 32+ed25519.PublicKeySize {
+		return nil, fmt.Errorf("malformed peer auth frame")
+	}
+	var claimedAddr agent.AgentAddress
+	copy(claimedAddr[:], peerAuth[:32])
+	claimedPub := ed25519.PublicKey(peerAuth[32 : 32+ed25519.PublicKeySize])
+	claimedSig := peerAuth[32+ed25519.PublicKeySize:]
+
+	registeredPub, ok := registry.Lookup(claimedAddr)
+	if !ok {
+		return nil, fmt.Errorf("no registered identity key for peer %s", claimedAddr)
+	}
+	if !claimedPub.Equal(registeredPub) {
+		return nil, fmt.Errorf("peer %s presented a public key that does not match its registered identity", claimedAddr)
+	}
+	if expectedPeer != (agent.AgentAddress{}) && claimedAddr != expectedPeer {
+		return nil, fmt.Errorf("peer authenticated as %s, expected %s", claimedAddr, expectedPeer)
+	}
+	if !ed25519.Verify(registeredPub, transcript, claimedSig) {
+		return nil, fmt.Errorf("peer %s failed to prove possession of its identity key", claimedAddr)
+	}
+
+	return &Session{
+		conn:      conn,
+		peer:      claimedAddr,
+		sendAEAD:  sendAEAD,
+		recvAEAD:  recvAEAD,
+		sendNonce: sendCounter,
+		recvNonce: recvCounter,
+	}, nil
+}
+
+// nonceFor renders counter as a 24-byte big-endian nonce and advances it, so
+// every sealed frame on a direction uses a nonce exactly one higher than the
+// last.
+func nonceFor(counter *uint64) []byte {
+	nonce := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(nonce[nonceSize-8:], *counter)
+	*counter++
+	return nonce
+}
+
+func writeFrame(w io.Writer, payload []byte) error {
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(len(payload)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
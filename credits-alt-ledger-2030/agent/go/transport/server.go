@@ -0,0 +1,14 @@
+package transport
+
+import (
+	"io"
+)
+
+// Accept performs the responder side of the STS handshake on an inbound
+// connection and returns the resulting authenticated Session. Callers loop
+// over their listener's accepted connections, call Accept, and then read
+// envelopes off the returned Session to dispatch prepare/commit/abort and
+// termination-protocol requests to their local coordinator.
+func Accept(conn io.ReadWriteCloser, local Identity, registry IdentityRegistry) (*Session, error) {
+	return Handshake(conn, local, registry, false, [32]byte{})
+}